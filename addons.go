@@ -1,6 +1,10 @@
 package seq
 
 import (
+	"bytes"
+	"fmt"
+	"io"
+	"unicode/utf8"
 )
 // Seq Add-on for tracking the last error received by `Next()`. Can be used to check if the Seq completed normally (io.EOF),
 // or if some other error happened.
@@ -61,17 +65,46 @@ func (o *HasIter[T]) IterNoArg() IterFunc0 {
 	return IterNoArg(o.sq)
 }
 
+// Location in a text data source, for error messages and the like. Line and Col are 1-based; Col counts
+// runes and ByteCol counts bytes, so they differ on lines with multibyte characters. Offset is the byte
+// offset from the start of the data source. Filename is optional.
+type Position struct {
+	Filename string
+	Offset   int
+	Line     int
+	Col      int
+	ByteCol  int
+}
+
+// Format as `file:line:col`, or `line:col` if there's no filename
+func (p Position) String() string {
+	if p.Filename == "" {
+		return fmt.Sprintf("%d:%d", p.Line, p.Col)
+	}
+	return fmt.Sprintf("%s:%d:%d", p.Filename, p.Line, p.Col)
+}
+
 // Add-on for tracking position of the underlying data source. Example: io.Reader character position
 // for a Seq of lines or tokens. Two positions are available: the last element returned by Next(), and
 // the current position that Next() will use the next time it's called.
+//
+// Position() and LastPosition() are byte offsets. For text, Location() and LastLocation() add the line
+// and column, provided Next() reports what it read with UpdateRune() or UpdateText() rather than Update().
 type HasPosition struct {
-	lastPos int
-	pos     int
+	lastPos     int
+	pos         int
+	filename    string
+	line        int
+	col         int
+	byteCol     int
+	lastLine    int
+	lastCol     int
+	lastByteCol int
 }
 
 // C'tor function
 func NewHasPosition() *HasPosition {
-	return &HasPosition{lastPos: 0, pos: 0}
+	return &HasPosition{lastPos: 0, pos: 0, line: 1, col: 1, byteCol: 1, lastLine: 1, lastCol: 1, lastByteCol: 1}
 }
 
 // Position of last returned element
@@ -84,10 +117,99 @@ func (o *HasPosition) Position() int {
 	return o.pos
 }
 
-// Rotate Position to LastPosition, increment new position by n, and return the previous position
+// Line and column of last returned element
+func (o *HasPosition) LastLocation() Position {
+	return Position{o.filename, o.lastPos, o.lastLine, o.lastCol, o.lastByteCol}
+}
+
+// Line and column of next element
+func (o *HasPosition) Location() Position {
+	return Position{o.filename, o.pos, o.line, o.col, o.byteCol}
+}
+
+// Filename reported by Location() and LastLocation()
+func (o *HasPosition) Filename() string {
+	return o.filename
+}
+
+// Set the filename reported by Location() and LastLocation()
+func (o *HasPosition) SetFilename(filename string) *HasPosition {
+	o.filename = filename
+	return o
+}
+
+// Rotate Position to LastPosition, increment new position by n, and return the previous position.
+// Only the byte offset moves; the line and column are left alone.
 func (o *HasPosition) Update(n int) int {
 	o.lastPos = o.pos
+	o.lastLine, o.lastCol, o.lastByteCol = o.line, o.col, o.byteCol
 	o.pos += n
 	return o.pos
 }
 
+// Like Update(), for a single rune of size bytes. Also moves the line and column.
+func (o *HasPosition) UpdateRune(ru rune, size int) int {
+	o.Update(size)
+	if size > 0 {
+		o.advance(ru, size)
+	}
+	return o.pos
+}
+
+// Like Update(), for a string of text. Also moves the line and column.
+func (o *HasPosition) UpdateText(text string) int {
+	o.Update(len(text))
+	for len(text) > 0 {
+		ru, size := utf8.DecodeRuneInString(text)
+		o.advance(ru, size)
+		text = text[size:]
+	}
+	return o.pos
+}
+
+// Move the current position forward over one rune, without rotating it to LastPosition. For Seqs that
+// read an element piece by piece: call Update(0) first, then move() for each rune.
+func (o *HasPosition) move(ru rune, size int) {
+	o.pos += size
+	o.advance(ru, size)
+}
+
+// Like move(), for a run of bytes. Counts lines and runes in bulk rather than rune by rune.
+func (o *HasPosition) moveBytes(b []byte) {
+	o.pos += len(b)
+	if i := bytes.LastIndexByte(b, '\n'); i >= 0 {
+		o.line += bytes.Count(b, []byte{'\n'})
+		rest := b[i+1:]
+		o.col, o.byteCol = 1+utf8.RuneCount(rest), 1+len(rest)
+		return
+	}
+	o.col += utf8.RuneCount(b)
+	o.byteCol += len(b)
+}
+
+// Set the current position and location, eg to match an inner Seq. The filename is left alone.
+func (o *HasPosition) setLocation(p Position) {
+	o.pos, o.line, o.col, o.byteCol = p.Offset, p.Line, p.Col, p.ByteCol
+}
+
+// Like setLocation(), for the position of the last element
+func (o *HasPosition) setLastLocation(p Position) {
+	o.lastPos, o.lastLine, o.lastCol, o.lastByteCol = p.Offset, p.Line, p.Col, p.ByteCol
+}
+
+func (o *HasPosition) advance(ru rune, size int) {
+	if ru == '\n' {
+		o.line++
+		o.col, o.byteCol = 1, 1
+		return
+	}
+	o.col++
+	o.byteCol += size
+}
+
+// If rd knows its own name (eg *os.File), use that as the filename
+func (o *HasPosition) setFilenameFrom(rd io.Reader) {
+	if named, ok := rd.(interface{ Name() string }); ok {
+		o.filename = named.Name()
+	}
+}
@@ -0,0 +1,212 @@
+package seq
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// Returned when reading the contents of a tar entry after the ArchiveSeq has moved past it
+var ErrEntryClosed = errors.New("archive entry no longer available")
+
+// A file (or directory, link etc) in an archive. Header is the *tar.Header or *zip.FileHeader it came
+// from, for anything not copied into the other fields.
+type Entry struct {
+	Name    string
+	Size    int64
+	Mode    fs.FileMode
+	ModTime time.Time
+	Header  any
+	src     *entrySource
+}
+
+// Where an Entry's contents come from. Tar entries can only be read while they're the current entry;
+// zip entries can be read at any time.
+type entrySource struct {
+	archive *ArchiveSeq
+	index   int
+	file    *zip.File
+}
+
+// True for a directory
+func (e Entry) IsDir() bool {
+	return e.Mode.IsDir()
+}
+
+// True for a regular file, as opposed to a directory, link, device etc
+func (e Entry) IsRegular() bool {
+	return e.Mode.IsRegular()
+}
+
+// Open the entry's contents. Contents of a tar entry can only be read until the next call to the
+// ArchiveSeq's Next(); after that, reads return ErrEntryClosed.
+func (e Entry) Open() (io.ReadCloser, error) {
+	if e.src == nil {
+		return nil, ErrEntryClosed
+	}
+	if e.src.file != nil {
+		return e.src.file.Open()
+	}
+	return io.NopCloser(&tarEntryReader{e.src}), nil
+}
+
+// Return a LineSeq over the entry's contents, with the entry's name as its filename
+func (e Entry) Lines() *LineSeq {
+	return e.LinesWithOptions(LineSeqOptions{})
+}
+
+// Like Lines(), with options, eg `LineSeqOptions{Compression: CompressionAuto}` for a .gz file in
+// the archive
+func (e Entry) LinesWithOptions(opts LineSeqOptions) *LineSeq {
+	sq := NewLineSeqWithOptions(&lazyReader{open: e.Open}, opts)
+	sq.SetFilename(e.Name)
+	return sq
+}
+
+// Reader for the current tar entry, which fails once the archive has moved on
+type tarEntryReader struct {
+	src *entrySource
+}
+
+func (r *tarEntryReader) Read(p []byte) (int, error) {
+	if r.src.archive.index != r.src.index {
+		return 0, ErrEntryClosed
+	}
+	return r.src.archive.tr.Read(p)
+}
+
+// Reader that opens its source on the first Read() and closes it at the end, so that a LineSeq can be
+// handed out without anything to close
+type lazyReader struct {
+	open func() (io.ReadCloser, error)
+	rc   io.ReadCloser
+	err  error
+}
+
+func (r *lazyReader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	if r.rc == nil {
+		r.rc, r.err = r.open()
+		if r.err != nil {
+			return 0, r.err
+		}
+	}
+	n, err := r.rc.Read(p)
+	if err != nil {
+		r.rc.Close()
+		r.err = err
+	}
+	return n, err
+}
+
+// Seq of the entries of a tar or zip archive, in the order they're stored.
+//
+// Add-ons: HasErr, HasIter
+//
+// Tar archives are read as a stream, so the contents of each entry have to be read before moving on to
+// the next one. Zip archives are read through their central directory, and entries can be read at any
+// time.
+type ArchiveSeq struct {
+	*HasErr
+	*HasIter[Entry]
+	tr     *tar.Reader
+	files  []*zip.File
+	index  int
+	closer io.Closer
+}
+
+func newArchiveSeq() *ArchiveSeq {
+	sq := &ArchiveSeq{HasErr: NewHasErr()}
+	// HasIter needs the Seq object so it needs special treatment
+	sq.HasIter = NewHasIter(sq)
+	return sq
+}
+
+// C'tor function for a tar archive. Compressed archives (.tar.gz, .tar.bz2) are decompressed
+// automatically.
+func NewTarSeq(rd io.Reader) *ArchiveSeq {
+	sq := newArchiveSeq()
+	sq.tr = tar.NewReader(NewDecompressingReader(rd, CompressionAuto))
+	return sq
+}
+
+// C'tor function for a zip archive, which needs random access to the archive and its size
+func NewZipSeq(ra io.ReaderAt, size int64) (*ArchiveSeq, error) {
+	zr, err := zip.NewReader(ra, size)
+	if err != nil {
+		return nil, err
+	}
+	sq := newArchiveSeq()
+	sq.files = zr.File
+	return sq, nil
+}
+
+// Open a tar, compressed tar or zip file, telling them apart by their contents rather than their name.
+// Call Close() when done.
+func OpenArchive(path string) (*ArchiveSeq, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	magic := make([]byte, 4)
+	n, _ := io.ReadFull(f, magic)
+	var sq *ArchiveSeq
+	if bytes.Equal(magic[:n], []byte("PK\x03\x04")) || bytes.Equal(magic[:n], []byte("PK\x05\x06")) {
+		var info fs.FileInfo
+		if info, err = f.Stat(); err == nil {
+			sq, err = NewZipSeq(f, info.Size())
+		}
+	} else if _, err = f.Seek(0, io.SeekStart); err == nil {
+		sq = NewTarSeq(f)
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	sq.closer = f
+	return sq, nil
+}
+
+// Close the file opened by OpenArchive(). Does nothing for an ArchiveSeq made with NewTarSeq() or
+// NewZipSeq().
+func (seq *ArchiveSeq) Close() error {
+	if seq.closer == nil {
+		return nil
+	}
+	return seq.closer.Close()
+}
+
+// Number of entries returned so far
+func (seq *ArchiveSeq) Index() int {
+	return seq.index
+}
+
+// Return the next entry
+func (seq *ArchiveSeq) Next() (Entry, error) {
+	if seq.tr == nil {
+		if seq.index >= len(seq.files) {
+			seq.lastErr = io.EOF
+			return Entry{}, io.EOF
+		}
+		file := seq.files[seq.index]
+		seq.index++
+		seq.lastErr = nil
+		return Entry{file.Name, int64(file.UncompressedSize64), file.Mode(), file.Modified, &file.FileHeader, &entrySource{file: file}}, nil
+	}
+	// Errors are sticky: tar.Reader keeps returning the same one
+	hdr, err := seq.tr.Next()
+	if err != nil {
+		seq.lastErr = err
+		return Entry{}, err
+	}
+	seq.index++
+	seq.lastErr = nil
+	return Entry{hdr.Name, hdr.Size, hdr.FileInfo().Mode(), hdr.ModTime, hdr, &entrySource{archive: seq, index: seq.index}}, nil
+}
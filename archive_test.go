@@ -0,0 +1,150 @@
+package seq
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var archiveFiles = []struct{ name, body string }{
+	{"names/", ""},
+	{"names/dogs.txt", "Rex\nFido\nSpot\n"},
+	{"names/cats.txt", "Zoë\nTom\n"},
+	{"README", "Pet names\n"},
+}
+
+func makeTar(t *testing.T) []byte {
+	var b bytes.Buffer
+	tw := tar.NewWriter(&b)
+	for _, file := range archiveFiles {
+		hdr := &tar.Header{Name: file.name, Mode: 0o644, Size: int64(len(file.body)), Typeflag: tar.TypeReg}
+		if strings.HasSuffix(file.name, "/") {
+			hdr.Mode, hdr.Typeflag = 0o755, tar.TypeDir
+		}
+		assert.NoError(t, tw.WriteHeader(hdr))
+		_, err := tw.Write([]byte(file.body))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, tw.Close())
+	return b.Bytes()
+}
+
+func makeZip(t *testing.T) []byte {
+	var b bytes.Buffer
+	zw := zip.NewWriter(&b)
+	for _, file := range archiveFiles {
+		wr, err := zw.Create(file.name)
+		assert.NoError(t, err)
+		_, err = wr.Write([]byte(file.body))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, zw.Close())
+	return b.Bytes()
+}
+
+// Lines of every .txt file in the archive that contain an 'o', as "name:line: text"
+func txtLinesWithO(t *testing.T, sq *ArchiveSeq) []string {
+	found := []string{}
+	entries := Where[Entry](sq, func(e Entry) bool { return e.IsRegular() && strings.HasSuffix(e.Name, ".txt") })
+	for entry := range Iter(entries) {
+		lines := entry.Lines()
+		for line := range Iter(Where[string](lines, func(line string) bool { return strings.Contains(line, "o") })) {
+			found = append(found, lines.LastLocation().String()+": "+line)
+		}
+		assert.True(t, errors.Is(lines.Err(), io.EOF))
+	}
+	return found
+}
+
+func TestTarSeq(t *testing.T) {
+	expected := []string{"names/dogs.txt:2:1: Fido", "names/dogs.txt:3:1: Spot", "names/cats.txt:1:1: Zoë", "names/cats.txt:2:1: Tom"}
+	assert.Equal(t, expected, txtLinesWithO(t, NewTarSeq(bytes.NewReader(makeTar(t)))))
+	// Compressed
+	compressed := compress(t, CompressionGzip, string(makeTar(t)))
+	assert.Equal(t, expected, txtLinesWithO(t, NewTarSeq(bytes.NewReader(compressed))))
+}
+
+func TestTarSeqEntries(t *testing.T) {
+	sq := NewTarSeq(bytes.NewReader(makeTar(t)))
+	entry, err := sq.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, "names/", entry.Name)
+	assert.True(t, entry.IsDir())
+	entry, err = sq.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(14), entry.Size)
+	assert.Equal(t, "names/dogs.txt", entry.Header.(*tar.Header).Name)
+	// Once the archive moves on, the previous entry can't be read
+	sq.Next()
+	line, err := entry.Lines().Next()
+	assert.Equal(t, "", line)
+	assert.Equal(t, ErrEntryClosed, err)
+	assert.Equal(t, 3, sq.Index())
+	sq.Next()
+	_, err = sq.Next()
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, 4, sq.Index())
+}
+
+func TestTarSeqCorrupt(t *testing.T) {
+	data := makeTar(t)
+	sq := NewTarSeq(bytes.NewReader(data[:1030]))
+	sq.Next()
+	entry, err := sq.Next()
+	assert.NoError(t, err)
+	rc, err := entry.Open()
+	assert.NoError(t, err)
+	_, err = io.ReadAll(rc)
+	assert.True(t, errors.Is(err, io.ErrUnexpectedEOF))
+	_, err = sq.Next()
+	assert.True(t, errors.Is(err, io.ErrUnexpectedEOF))
+}
+
+func TestZipSeq(t *testing.T) {
+	data := makeZip(t)
+	sq, err := NewZipSeq(bytes.NewReader(data), int64(len(data)))
+	assert.NoError(t, err)
+	entries := collectSeq[Entry](t, sq)
+	assert.Len(t, entries, 4)
+	// Zip entries can be read in any order
+	assert.Equal(t, []string{"Pet names"}, collectSeq[string](t, entries[3].Lines()))
+	assert.Equal(t, []string{"Rex", "Fido", "Spot"}, collectSeq[string](t, entries[1].Lines()))
+	assert.Equal(t, "names/cats.txt", entries[2].Header.(*zip.FileHeader).Name)
+
+	sq, err = NewZipSeq(bytes.NewReader(data), int64(len(data)))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"names/dogs.txt:2:1: Fido", "names/dogs.txt:3:1: Spot", "names/cats.txt:1:1: Zoë", "names/cats.txt:2:1: Tom"}, txtLinesWithO(t, sq))
+
+	_, err = NewZipSeq(strings.NewReader("not a zip"), 9)
+	assert.True(t, errors.Is(err, zip.ErrFormat))
+}
+
+func TestOpenArchive(t *testing.T) {
+	dir := t.TempDir()
+	for name, data := range map[string][]byte{
+		"pets.zip":    makeZip(t),
+		"pets.tar":    makeTar(t),
+		"pets.tar.gz": compress(t, CompressionGzip, string(makeTar(t))),
+	} {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.WriteFile(path, data, 0o644))
+		sq, err := OpenArchive(path)
+		assert.NoError(t, err, name)
+		names := []string{}
+		for entry := range Iter(sq) {
+			names = append(names, entry.Name)
+		}
+		assert.Equal(t, []string{"names/", "names/dogs.txt", "names/cats.txt", "README"}, names, name)
+		assert.NoError(t, sq.Close())
+	}
+	_, err := OpenArchive(filepath.Join(dir, "nope.zip"))
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+}
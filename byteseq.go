@@ -0,0 +1,109 @@
+package seq
+
+import (
+	"bufio"
+	"errors"
+	"io"
+)
+
+// Default size of ByteSeq's bufio.Reader
+const defaultByteBufSize = 4096
+
+// Seq for expressing a Reader as a sequence of bytes, for binary data. The byte-oriented counterpart
+// of RuneSeq.
+//
+// Add-ons: HasErr, HasIter, HasPosition
+//
+// Note that a 0 byte is the zero value, so a 0 byte comes back as (0, nil) while the end of the data is
+// (0, io.EOF). Check the error, not the value.
+type ByteSeq struct {
+	*HasErr
+	*HasIter[byte]
+	*HasPosition
+	rd  io.Reader
+	brd *bufio.Reader
+}
+
+// C'tor function
+func NewByteSeq(rd io.Reader) *ByteSeq {
+	return NewByteSeqSize(rd, defaultByteBufSize)
+}
+
+// C'tor function with a custom buffer size. Sizes below 16 are raised to 16.
+func NewByteSeqSize(rd io.Reader, size int) *ByteSeq {
+	sq := &ByteSeq{
+		HasErr:      NewHasErr(),
+		HasPosition: NewHasPosition(),
+		rd:          rd,
+		brd:         bufio.NewReaderSize(rd, size),
+	}
+	// HasIter needs the Seq object so it needs special treatment
+	sq.HasIter = NewHasIter(sq)
+	return sq
+}
+
+// Return the next byte in the sequence
+func (seq *ByteSeq) Next() (byte, error) {
+	b, err := seq.brd.ReadByte()
+	seq.lastErr = err
+	if err != nil {
+		seq.HasPosition.Update(0)
+		return 0, err
+	}
+	seq.HasPosition.Update(1)
+	return b, nil
+}
+
+// Seq for reading a Reader in fixed-size blocks.
+//
+// Add-ons: HasErr, HasIter, HasPosition
+//
+// Each call to Next() returns a new slice of size bytes, except for the last one, which holds whatever
+// was left and may be shorter.
+type ChunkSeq struct {
+	*HasErr
+	*HasIter[*[]byte]
+	*HasPosition
+	rd     io.Reader
+	size   int
+	errEnd error
+}
+
+// C'tor function
+func NewChunkSeq(rd io.Reader, size int) *ChunkSeq {
+	sq := &ChunkSeq{
+		HasErr:      NewHasErr(),
+		HasPosition: NewHasPosition(),
+		rd:          rd,
+		size:        max(size, 1),
+	}
+	// HasIter needs the Seq object so it needs special treatment
+	sq.HasIter = NewHasIter(sq)
+	return sq
+}
+
+// Return the next chunk
+func (seq *ChunkSeq) Next() (*[]byte, error) {
+	if seq.errEnd != nil {
+		seq.HasPosition.Update(0)
+		seq.lastErr = seq.errEnd
+		return nil, seq.errEnd
+	}
+	chunk := make([]byte, seq.size)
+	n, err := io.ReadFull(seq.rd, chunk)
+	seq.HasPosition.Update(n)
+	// A short final chunk isn't an error; EOF is reported by the next call
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		err = io.EOF
+	}
+	if err != nil {
+		seq.errEnd = err
+	}
+	if n == 0 {
+		seq.lastErr = err
+		return nil, err
+	}
+	chunk = chunk[:n]
+	seq.lastErr = nil
+	return &chunk, nil
+}
@@ -0,0 +1,67 @@
+package seq
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestByteSeq(t *testing.T) {
+	data := []byte{0x00, 0xff, 'a', 0x00}
+	sq := NewByteSeqSize(bytes.NewReader(data), 16)
+	vals := []byte{}
+	for {
+		b, err := sq.Next()
+		if err != nil {
+			assert.Equal(t, io.EOF, err)
+			break
+		}
+		vals = append(vals, b)
+	}
+	assert.Equal(t, data, vals)
+	assert.Equal(t, 4, sq.Position())
+	assert.True(t, errors.Is(sq.Err(), io.EOF))
+}
+
+func TestByteSeqWhere(t *testing.T) {
+	sq := Where(NewByteSeq(strings.NewReader("a1b2c3")), func(b byte) bool { return b >= '0' && b <= '9' })
+	assert.Equal(t, []byte("123"), collectSeq[byte](t, sq))
+}
+
+func TestChunkSeq(t *testing.T) {
+	sq := NewChunkSeq(strings.NewReader("abcdefgh"), 3)
+	chunks := []string{}
+	for chunk := range sq.Iter() {
+		if chunk != nil {
+			chunks = append(chunks, string(*chunk))
+		}
+	}
+	assert.Equal(t, []string{"abc", "def", "gh"}, chunks)
+	assert.Equal(t, 8, sq.Position())
+	assert.True(t, errors.Is(sq.Err(), io.EOF))
+	testEof[*[]byte](t, sq)
+}
+
+func TestChunkSeqExact(t *testing.T) {
+	sq := NewChunkSeq(strings.NewReader("abcdef"), 3)
+	n, err := Count(sq)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, n)
+}
+
+func TestRuneSeqSize(t *testing.T) {
+	sq := NewRuneSeqSize(strings.NewReader(TS0), 4096)
+	n, err := Count(sq)
+	assert.Nil(t, err)
+	assert.Equal(t, len(TS0), n)
+}
+
+func TestLineSeqBufferSize(t *testing.T) {
+	// A tiny buffer still handles lines longer than the buffer
+	sq := NewLineSeqWithOptions(strings.NewReader("Abigail\nAJ\n"), LineSeqOptions{BufferSize: 2})
+	assert.Equal(t, []string{"Abigail", "AJ"}, collectSeq[string](t, sq))
+}
@@ -0,0 +1,79 @@
+package seq
+
+import (
+	"io"
+)
+
+// Saved position of a LineSeq or RuneSeq, for resuming a long job where it left off. Offset is the byte
+// offset of the next element, Index is the number of elements returned before it, and Line/Col/ByteCol
+// restore Location() so that positions after resuming match the original run.
+//
+// Checkpoint has JSON tags, so it can be persisted with encoding/json:
+//
+//	{"offset":1043,"index":131,"line":132,"col":1,"byteCol":1}
+type Checkpoint struct {
+	Offset  int64 `json:"offset"`
+	Index   int   `json:"index"`
+	Line    int   `json:"line"`
+	Col     int   `json:"col"`
+	ByteCol int   `json:"byteCol"`
+}
+
+func (o *HasPosition) checkpoint(index int) Checkpoint {
+	return Checkpoint{int64(o.pos), index, o.line, o.col, o.byteCol}
+}
+
+func (o *HasPosition) restore(cp Checkpoint) {
+	o.pos, o.lastPos = int(cp.Offset), int(cp.Offset)
+	o.line, o.col, o.byteCol = cp.Line, cp.Col, cp.ByteCol
+	o.lastLine, o.lastCol, o.lastByteCol = cp.Line, cp.Col, cp.ByteCol
+}
+
+// Return a Checkpoint for the next line. Pass it to ResumeLineSeq() to carry on from here.
+func (seq *LineSeq) Checkpoint() Checkpoint {
+	return seq.HasPosition.checkpoint(seq.index)
+}
+
+// Return a Checkpoint for the next rune. Pass it to ResumeRuneSeq() to carry on from here.
+func (seq *RuneSeq) Checkpoint() Checkpoint {
+	return seq.HasPosition.checkpoint(seq.index)
+}
+
+// Number of elements returned so far
+func (seq *LineSeq) Index() int {
+	return seq.index
+}
+
+// Number of elements returned so far
+func (seq *RuneSeq) Index() int {
+	return seq.index
+}
+
+// Seek rs to the checkpoint's offset and return a LineSeq that continues from there, with its position
+// and index picking up where the checkpointed LineSeq left off
+func ResumeLineSeq(rs io.ReadSeeker, cp Checkpoint) (*LineSeq, error) {
+	return ResumeLineSeqWithOptions(rs, cp, LineSeqOptions{})
+}
+
+// Like ResumeLineSeq(), for a LineSeq created with NewLineSeqWithOptions()
+func ResumeLineSeqWithOptions(rs io.ReadSeeker, cp Checkpoint, opts LineSeqOptions) (*LineSeq, error) {
+	if _, err := rs.Seek(cp.Offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	sq := NewLineSeqWithOptions(rs, opts)
+	sq.HasPosition.restore(cp)
+	sq.index = cp.Index
+	return sq, nil
+}
+
+// Seek rs to the checkpoint's offset and return a RuneSeq that continues from there, with its position
+// and index picking up where the checkpointed RuneSeq left off
+func ResumeRuneSeq(rs io.ReadSeeker, cp Checkpoint) (*RuneSeq, error) {
+	if _, err := rs.Seek(cp.Offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	sq := NewRuneSeq(rs)
+	sq.HasPosition.restore(cp)
+	sq.index = cp.Index
+	return sq, nil
+}
@@ -0,0 +1,59 @@
+package seq
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLineSeqCheckpointResume(t *testing.T) {
+	f, err := os.Open("./petnames.txt")
+	assert.Nil(t, err)
+	defer f.Close()
+	sq := NewLineSeq(f)
+	for range 12 {
+		_, err = sq.Next()
+		assert.Nil(t, err)
+	}
+	cp := sq.Checkpoint()
+	assert.Equal(t, 12, cp.Index)
+	assert.Equal(t, 13, cp.Line)
+	// Round trip through JSON, as a job persisting its progress would
+	data, err := json.Marshal(cp)
+	assert.Nil(t, err)
+	var cpLoaded Checkpoint
+	assert.Nil(t, json.Unmarshal(data, &cpLoaded))
+	assert.Equal(t, cp, cpLoaded)
+
+	f2, err := os.Open("./petnames.txt")
+	assert.Nil(t, err)
+	defer f2.Close()
+	sqResumed, err := ResumeLineSeq(f2, cpLoaded)
+	assert.Nil(t, err)
+	line, err := sqResumed.Next()
+	testNextOk(t, "Alf", line, err)
+	assert.Equal(t, 13, sqResumed.Index())
+	assert.Equal(t, 13, sqResumed.LastLocation().Line)
+	n, err := Count(sqResumed)
+	assert.Nil(t, err)
+	assert.Equal(t, 1000, 13+n)
+	assert.Equal(t, 1000, sqResumed.Index())
+}
+
+func TestRuneSeqCheckpointResume(t *testing.T) {
+	str := "Zoë\nRex"
+	sq := NewRuneSeq(strings.NewReader(str))
+	for range 4 {
+		sq.Next()
+	}
+	cp := sq.Checkpoint()
+	assert.Equal(t, Checkpoint{5, 4, 2, 1, 1}, cp)
+	sqResumed, err := ResumeRuneSeq(strings.NewReader(str), cp)
+	assert.Nil(t, err)
+	ru, err := sqResumed.Next()
+	testNextOk(t, 'R', ru, err)
+	assert.Equal(t, Position{"", 6, 2, 2, 2}, sqResumed.Location())
+}
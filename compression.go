@@ -0,0 +1,166 @@
+package seq
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+)
+
+// Compression format of a data source, for DecompressingReader and the sequences built on it
+type Compression int
+
+const (
+	// No decompression: bytes are passed through as-is. This is the default.
+	CompressionNone Compression = iota
+	// Detect the format from the magic bytes at the start of the input, falling back to no compression
+	// if they don't match anything. zlib's header is too short to be sure of, so it's only detected if
+	// the start of the stream also decompresses, and only with the usual 32KB window. Raw flate streams
+	// have no magic bytes, so they're never detected.
+	CompressionAuto
+	CompressionGzip
+	CompressionBzip2
+	CompressionZlib
+	// Raw DEFLATE data, with no header. It can't be detected, so it has to be asked for.
+	CompressionFlate
+)
+
+func (c Compression) String() string {
+	switch c {
+	case CompressionAuto:
+		return "auto"
+	case CompressionGzip:
+		return "gzip"
+	case CompressionBzip2:
+		return "bzip2"
+	case CompressionZlib:
+		return "zlib"
+	case CompressionFlate:
+		return "flate"
+	default:
+		return "none"
+	}
+}
+
+// Most bytes sniffCompression() decompresses to check for zlib. Only bytes that are already buffered are
+// used, so that sniffing doesn't wait on a pipe for more input.
+const sniffLen = 512
+
+var (
+	magicGzip  = []byte{0x1f, 0x8b}
+	magicBzip2 = []byte("BZh")
+	// What follows "BZh" and the block size in a bzip2 stream: a block header, or the end of an empty
+	// stream
+	magicBzip2Block = []byte{0x31, 0x41, 0x59, 0x26, 0x53, 0x59}
+	magicBzip2End   = []byte{0x17, 0x72, 0x45, 0x38, 0x50, 0x90}
+)
+
+// Work out the compression format from the start of the input. Only peeks as far as it needs to, so
+// that uncompressed input arriving a little at a time (eg from a pipe) isn't held up.
+func sniffCompression(src *bufio.Reader) Compression {
+	peek, _ := src.Peek(3)
+	switch {
+	// gzip has only one compression method, deflate (8)
+	case bytes.HasPrefix(peek, magicGzip) && len(peek) > 2 && peek[2] == 8:
+		return CompressionGzip
+	case bytes.HasPrefix(peek, magicBzip2):
+		peek, _ = src.Peek(10)
+		if len(peek) == 10 && peek[3] >= '1' && peek[3] <= '9' &&
+			(bytes.Equal(peek[4:], magicBzip2Block) || bytes.Equal(peek[4:], magicBzip2End)) {
+			return CompressionBzip2
+		}
+	// zlib: deflate with the usual 32KB window, no preset dictionary, and a header checksum that's a
+	// multiple of 31. Plenty of text gets past that (eg "x^2"), so check that what follows decompresses.
+	case len(peek) >= 2 && peek[0] == 0x78 && peek[1]&0x20 == 0 && (uint(peek[0])<<8|uint(peek[1]))%31 == 0:
+		peek, _ = src.Peek(min(src.Buffered(), sniffLen))
+		if isZlib(peek) {
+			return CompressionZlib
+		}
+	}
+	return CompressionNone
+}
+
+// Whether peek is the start of a valid zlib stream. A stream longer than peek is cut short, which is
+// fine; corrupt data isn't.
+func isZlib(peek []byte) bool {
+	zr, err := zlib.NewReader(bytes.NewReader(peek))
+	if err != nil {
+		return false
+	}
+	_, err = io.Copy(io.Discard, zr)
+	return err == nil || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// Reader that decompresses gzip, bzip2, zlib or raw flate data, using the standard library. With
+// CompressionAuto the format is detected from the magic bytes at the start of the input, so compressed
+// and uncompressed input can be read the same way. Detection happens on the first Read(), or the first
+// call to Compression(), whichever comes first.
+//
+// Concatenated gzip members (as produced by `cat a.gz b.gz`) are read as one stream. Errors in the
+// compressed data, including a bad gzip header, are returned by Read().
+type DecompressingReader struct {
+	src       *bufio.Reader
+	comp      Compression
+	isSniffed bool
+	rd        io.Reader
+	err       error
+}
+
+// C'tor function. Pass CompressionAuto to sniff the format, or a specific format if it's known.
+func NewDecompressingReader(rd io.Reader, comp Compression) *DecompressingReader {
+	return &DecompressingReader{
+		src:  bufio.NewReader(rd),
+		comp: comp,
+	}
+}
+
+// Return the compression format. For CompressionAuto this is the detected format, which may mean
+// reading the first few bytes of the source.
+func (d *DecompressingReader) Compression() Compression {
+	d.sniff()
+	return d.comp
+}
+
+// Settle on a format and set up the decompressor
+func (d *DecompressingReader) sniff() {
+	if d.isSniffed {
+		return
+	}
+	d.isSniffed = true
+	if d.comp == CompressionAuto {
+		d.comp = sniffCompression(d.src)
+	}
+	switch d.comp {
+	case CompressionGzip:
+		d.rd, d.err = gzip.NewReader(d.src)
+	case CompressionBzip2:
+		d.rd = bzip2.NewReader(d.src)
+	case CompressionZlib:
+		d.rd, d.err = zlib.NewReader(d.src)
+	case CompressionFlate:
+		d.rd = flate.NewReader(d.src)
+	default:
+		d.rd = d.src
+	}
+}
+
+// Read decompressed data
+func (d *DecompressingReader) Read(p []byte) (int, error) {
+	d.sniff()
+	if d.err != nil {
+		return 0, d.err
+	}
+	return d.rd.Read(p)
+}
+
+// Release the decompressor. The source isn't closed.
+func (d *DecompressingReader) Close() error {
+	if closer, ok := d.rd.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+package seq
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// "Rex\nFido\nSpot\n" compressed with bzip2, which the standard library can only read
+const bzip2Pets = "425a6839314159265359d9aa156500000247800010010018000620c44020003100d34d0401a322469c81c8eaf177245385090d9aa15650"
+
+func compress(t *testing.T, comp Compression, data string) []byte {
+	var b bytes.Buffer
+	var wr io.WriteCloser
+	switch comp {
+	case CompressionGzip:
+		wr = gzip.NewWriter(&b)
+	case CompressionZlib:
+		wr = zlib.NewWriter(&b)
+	case CompressionFlate:
+		wr, _ = flate.NewWriter(&b, flate.DefaultCompression)
+	case CompressionBzip2:
+		bz, err := hex.DecodeString(bzip2Pets)
+		assert.NoError(t, err)
+		assert.Equal(t, "Rex\nFido\nSpot\n", data)
+		return bz
+	default:
+		return []byte(data)
+	}
+	_, err := wr.Write([]byte(data))
+	assert.NoError(t, err)
+	assert.NoError(t, wr.Close())
+	return b.Bytes()
+}
+
+func TestCompressionString(t *testing.T) {
+	assert.Equal(t, "gzip", CompressionGzip.String())
+	assert.Equal(t, "none", CompressionNone.String())
+}
+
+func TestDecompressingReaderAuto(t *testing.T) {
+	pets := "Rex\nFido\nSpot\n"
+	for _, comp := range []Compression{CompressionNone, CompressionGzip, CompressionBzip2, CompressionZlib} {
+		d := NewDecompressingReader(bytes.NewReader(compress(t, comp, pets)), CompressionAuto)
+		assert.Equal(t, comp, d.Compression())
+		data, err := io.ReadAll(d)
+		assert.NoError(t, err, comp)
+		assert.Equal(t, pets, string(data), comp)
+		assert.NoError(t, d.Close())
+	}
+}
+
+func TestDecompressingReaderLookalikes(t *testing.T) {
+	// Text that starts like a zlib, gzip or bzip2 header
+	for _, text := range []string{"Hjalmar\nRex\n", "HKarl\n", "800 Main St\n", "8n\n", "x^2 + 1\n", "Xfoo\n", "(r)\n",
+		"hb\n", "x", "\x1f\x8b", "BZh9 is a bad name\n", "BZh"} {
+		d := NewDecompressingReader(strings.NewReader(text), CompressionAuto)
+		assert.Equal(t, CompressionNone, d.Compression(), "%q", text)
+		sq := NewLineSeqWithOptions(strings.NewReader(text), LineSeqOptions{Compression: CompressionAuto})
+		assert.Equal(t, strings.Split(strings.TrimSuffix(text, "\n"), "\n"), collectSeq[string](t, sq), "%q", text)
+		assert.Equal(t, io.EOF, sq.Err())
+	}
+	// A tar file whose first entry's name looks like a zlib header
+	var b bytes.Buffer
+	tw := tar.NewWriter(&b)
+	assert.NoError(t, tw.WriteHeader(&tar.Header{Name: "Hjalmar.txt", Mode: 0o644, Size: 4, Typeflag: tar.TypeReg}))
+	_, err := tw.Write([]byte("Rex\n"))
+	assert.NoError(t, err)
+	assert.NoError(t, tw.Close())
+	entry, err := NewTarSeq(&b).Next()
+	assert.NoError(t, err)
+	assert.Equal(t, "Hjalmar.txt", entry.Name)
+}
+
+func TestLineSeqCompressionPipe(t *testing.T) {
+	// Text that passes the zlib header check mustn't make sniffing wait for more than has arrived
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	go pw.Write([]byte("x^2 + y\n"))
+	sq := NewLineSeqWithOptions(pr, LineSeqOptions{Compression: CompressionAuto})
+	var line string
+	var err error
+	done := make(chan struct{})
+	go func() {
+		line, err = sq.Next()
+		close(done)
+	}()
+	select {
+	case <-done:
+		testNextOk(t, "x^2 + y", line, err)
+	case <-time.After(time.Second):
+		t.Fatal("Next() blocked waiting for more input")
+	}
+}
+
+func TestDecompressingReaderFlate(t *testing.T) {
+	// Raw flate has no magic bytes, so it has to be asked for
+	compressed := compress(t, CompressionFlate, "Rex\nFido\n")
+	data, err := io.ReadAll(NewDecompressingReader(bytes.NewReader(compressed), CompressionFlate))
+	assert.NoError(t, err)
+	assert.Equal(t, "Rex\nFido\n", string(data))
+	assert.Equal(t, CompressionNone, NewDecompressingReader(bytes.NewReader(compressed), CompressionAuto).Compression())
+}
+
+func TestDecompressingReaderConcatenated(t *testing.T) {
+	compressed := append(compress(t, CompressionGzip, "Rex\n"), compress(t, CompressionGzip, "Fido\n")...)
+	sq := NewLineSeqWithOptions(bytes.NewReader(compressed), LineSeqOptions{Compression: CompressionAuto})
+	assert.Equal(t, []string{"Rex", "Fido"}, collectSeq[string](t, sq))
+}
+
+func TestDecompressingReaderErrors(t *testing.T) {
+	// A gzip header, then garbage
+	garbage := []byte{0x1f, 0x8b, 8, 0, 0, 0, 0, 0, 0, 0xff, 0xff, 0xff, 0xff}
+	sq := NewLineSeqWithOptions(bytes.NewReader(garbage), LineSeqOptions{Compression: CompressionAuto})
+	line, err := sq.Next()
+	assert.Equal(t, "", line)
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, io.EOF))
+	// Uncompressed input that's asked to be gzip
+	_, err = io.ReadAll(NewDecompressingReader(strings.NewReader("Rex\nFido\nSpot\n"), CompressionGzip))
+	assert.True(t, errors.Is(err, gzip.ErrHeader))
+	// Truncated compressed data
+	compressed := compress(t, CompressionGzip, strings.Repeat("Rex\n", 100))
+	_, err = io.ReadAll(NewDecompressingReader(bytes.NewReader(compressed[:len(compressed)/2]), CompressionAuto))
+	assert.True(t, errors.Is(err, io.ErrUnexpectedEOF))
+}
+
+func TestLineSeqCompression(t *testing.T) {
+	compressed := compress(t, CompressionZlib, "Zoë\r\nRex\r\n")
+	sq := NewLineSeqWithOptions(bytes.NewReader(compressed), LineSeqOptions{Compression: CompressionAuto, LineEnding: LineEndingCRLF})
+	assert.Equal(t, CompressionZlib, sq.Compression())
+	assert.Equal(t, []string{"Zoë", "Rex"}, collectSeq[string](t, sq))
+	// Positions count decompressed bytes
+	assert.Equal(t, 11, sq.Position())
+	assert.Equal(t, CompressionNone, NewLineSeq(strings.NewReader("")).Compression())
+}
+
+func TestLineSeqCompressionEncoding(t *testing.T) {
+	utf16 := encodeUTF16("Rex\nZoë\n", binary.LittleEndian)
+	compressed := compress(t, CompressionGzip, string(utf16))
+	sq := NewLineSeqWithOptions(bytes.NewReader(compressed), LineSeqOptions{Compression: CompressionAuto, Encoding: EncodingAuto})
+	assert.Equal(t, []string{"Rex", "Zoë"}, collectSeq[string](t, sq))
+	assert.Equal(t, EncodingUTF16LE, sq.Encoding())
+}
+
+func writeTestFile(t *testing.T, name string, data []byte) string {
+	path := filepath.Join(t.TempDir(), name)
+	assert.NoError(t, os.WriteFile(path, data, 0o644))
+	return path
+}
+
+func TestFileFlcCompressed(t *testing.T) {
+	names, err := os.ReadFile("./petnames.txt")
+	assert.NoError(t, err)
+	path := writeTestFile(t, "petnames.txt.gz", compress(t, CompressionGzip, string(names)))
+	for _, access := range []RandomAccess{RandomAccessRescan, RandomAccessIndex} {
+		flc := NewFileFlcWithOptions(path, LineSeqOptions{Compression: CompressionAuto}).SetRandomAccess(access)
+		n, err := flc.Count()
+		assert.NoError(t, err)
+		assert.Equal(t, 1000, n)
+		for i, expected := range map[int]string{0: "AJ", 63: "Bella", 64: "Belle", 999: "Zorro"} {
+			line, err := flc.GetLine(i)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, line, i)
+		}
+		assert.NoError(t, flc.Close())
+	}
+}
+
+func TestFileFlcIndex(t *testing.T) {
+	// No final newline, and an uncompressed file read with CompressionAuto isn't copied
+	path := writeTestFile(t, "pets.txt", []byte("Rex\n\nFido"))
+	flc := NewFileFlcWithOptions(path, LineSeqOptions{Compression: CompressionAuto}).SetRandomAccess(RandomAccessIndex)
+	n, err := flc.Count()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, "", flc.tmpPath)
+	for i, expected := range []string{"Rex", "", "Fido"} {
+		line, err := flc.GetLine(i)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, line)
+	}
+	_, err = flc.GetLine(3)
+	assert.Equal(t, io.EOF, err)
+
+	// Temporary file is removed by Close()
+	path = writeTestFile(t, "pets.txt.bz2", compress(t, CompressionBzip2, "Rex\nFido\nSpot\n"))
+	flc = NewFileFlcWithOptions(path, LineSeqOptions{Compression: CompressionAuto}).SetRandomAccess(RandomAccessIndex)
+	line, err := flc.GetLine(2)
+	assert.NoError(t, err)
+	assert.Equal(t, "Spot", line)
+	tmpPath := flc.tmpPath
+	assert.FileExists(t, tmpPath)
+	assert.NoError(t, flc.Close())
+	assert.NoFileExists(t, tmpPath)
+}
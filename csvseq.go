@@ -0,0 +1,512 @@
+package seq
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Returned by CSVStructSeq when there's no header to map columns to struct fields
+var ErrNoHeader = errors.New("no CSV header: set HasHeader or Header")
+
+// Options for NewCSVSeq(). The zero value reads standard comma-separated CSV without a header row.
+type CSVOptions struct {
+	// Field delimiter. 0 means ','; use '\t' for TSV.
+	Comma rune
+	// Lines starting with this character are skipped. 0 means no comments.
+	Comment rune
+	// Treat '"' as ordinary data rather than quoting, as in most TSV files. Fields can't contain the
+	// delimiter or newlines.
+	NoQuotes bool
+	// Allow quotes in unquoted fields, and unescaped quotes in quoted fields
+	LazyQuotes bool
+	// Ignore leading whitespace in fields
+	TrimLeadingSpace bool
+	// The first record is the header row. It's available from Header() and not returned by Next().
+	HasHeader bool
+	// Column names, for input without a header row. Ignored if HasHeader is set.
+	Header []string
+}
+
+// Error for a record whose field couldn't be converted to the type of its struct field. Parse errors
+// from the CSV itself are returned as *csv.ParseError, which also has the line number.
+type CSVError struct {
+	Line   int
+	Column string
+	Err    error
+}
+
+func (e *CSVError) Error() string {
+	return fmt.Sprintf("line %d, column %q: %v", e.Line, e.Column, e.Err)
+}
+
+func (e *CSVError) Unwrap() error {
+	return e.Err
+}
+
+// Source of records for CSVSeq: encoding/csv, or a LineSeq for unquoted input
+type recordReader interface {
+	// Return the next record and the line it starts on
+	read() ([]string, int, error)
+	// Byte offset after the last record read
+	offset() int
+}
+
+type quotedReader struct {
+	rdr      *csv.Reader
+	baseLine int
+	baseOff  int
+}
+
+func (rr *quotedReader) read() ([]string, int, error) {
+	record, err := rr.rdr.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			parseErr.StartLine += rr.baseLine
+			parseErr.Line += rr.baseLine
+		}
+		return nil, 0, err
+	}
+	line, _ := rr.rdr.FieldPos(0)
+	return record, rr.baseLine + line, nil
+}
+
+func (rr *quotedReader) offset() int {
+	return rr.baseOff + int(rr.rdr.InputOffset())
+}
+
+type unquotedReader struct {
+	lines   *LineSeq
+	sep     string
+	comment rune
+	nFields int
+}
+
+func (rr *unquotedReader) read() ([]string, int, error) {
+	for {
+		line, err := rr.lines.Next()
+		if line == "" || (rr.comment != 0 && strings.HasPrefix(line, string(rr.comment))) {
+			if err != nil {
+				return nil, 0, err
+			}
+			continue
+		}
+		lineNum := rr.lines.LastLocation().Line
+		record := strings.Split(line, rr.sep)
+		// Same rule as encoding/csv: every record has as many fields as the first
+		if rr.nFields == 0 {
+			rr.nFields = len(record)
+		} else if len(record) != rr.nFields {
+			return nil, 0, &csv.ParseError{StartLine: lineNum, Line: lineNum, Column: 1, Err: csv.ErrFieldCount}
+		}
+		return record, lineNum, nil
+	}
+}
+
+func (rr *unquotedReader) offset() int {
+	return rr.lines.Position()
+}
+
+// Seq of the records of a CSV or TSV file, each a pointer to a slice of fields
+//
+// Add-ons: HasErr, HasIter, HasPosition
+//
+// After each call to Next(), LastLocation().Line is the line the record starts on, and Location().Line
+// is the line after it. Position() is the byte offset after the record.
+//
+// A malformed record gives a *csv.ParseError, which includes its line number. The record is skipped,
+// and the next call to Next() carries on with the one after it.
+type CSVSeq struct {
+	*HasErr
+	*HasIter[*[]string]
+	*HasPosition
+	rdr       recordReader
+	opts      CSVOptions
+	header    []string
+	isStarted bool
+	index     int
+}
+
+// C'tor function
+func NewCSVSeq(rd io.Reader, opts CSVOptions) *CSVSeq {
+	return newCSVSeqAt(rd, opts, 0, 0)
+}
+
+// C'tor function for a reader that has been positioned at byte offset off, the start of line line+1
+func newCSVSeqAt(rd io.Reader, opts CSVOptions, off int, line int) *CSVSeq {
+	if opts.Comma == 0 {
+		opts.Comma = ','
+	}
+	sq := &CSVSeq{
+		HasErr:      NewHasErr(),
+		HasPosition: NewHasPosition(),
+		opts:        opts,
+	}
+	if opts.NoQuotes {
+		lines := NewLineSeqWithOptions(rd, LineSeqOptions{LineEnding: LineEndingCRLF})
+		lines.HasPosition.restore(Checkpoint{Offset: int64(off), Line: line + 1, Col: 1, ByteCol: 1})
+		sq.rdr = &unquotedReader{lines: lines, sep: string(opts.Comma), comment: opts.Comment}
+	} else {
+		rdr := csv.NewReader(rd)
+		rdr.Comma = opts.Comma
+		rdr.Comment = opts.Comment
+		rdr.LazyQuotes = opts.LazyQuotes
+		rdr.TrimLeadingSpace = opts.TrimLeadingSpace
+		sq.rdr = &quotedReader{rdr: rdr, baseLine: line, baseOff: off}
+	}
+	sq.HasPosition.setFilenameFrom(rd)
+	sq.HasPosition.restore(Checkpoint{Offset: int64(off), Line: line + 1, Col: 1, ByteCol: 1})
+	// HasIter needs the Seq object so it needs special treatment
+	sq.HasIter = NewHasIter(sq)
+	return sq
+}
+
+// Read a record and move the position past it
+func (seq *CSVSeq) read() ([]string, error) {
+	record, line, err := seq.rdr.read()
+	seq.HasPosition.Update(seq.rdr.offset() - seq.pos)
+	if err != nil {
+		return nil, err
+	}
+	// Quoted fields can span lines
+	nLines := 1
+	for _, field := range record {
+		nLines += strings.Count(field, "\n")
+	}
+	seq.lastLine, seq.lastCol, seq.lastByteCol = line, 1, 1
+	seq.line, seq.col, seq.byteCol = line+nLines, 1, 1
+	return record, nil
+}
+
+// Read the header row if there is one, the first time through
+func (seq *CSVSeq) start() error {
+	if seq.isStarted {
+		return nil
+	}
+	seq.isStarted = true
+	if !seq.opts.HasHeader {
+		seq.header = seq.opts.Header
+		return nil
+	}
+	header, err := seq.read()
+	seq.header = header
+	return err
+}
+
+// Return the column names: the header row if HasHeader is set (reading it if Next() hasn't been called
+// yet), otherwise the Header option
+func (seq *CSVSeq) Header() ([]string, error) {
+	if err := seq.start(); err != nil {
+		seq.lastErr = err
+		return nil, err
+	}
+	return seq.header, nil
+}
+
+// Return the index of the named column, or -1 if there's no such column
+func (seq *CSVSeq) Column(name string) int {
+	header, _ := seq.Header()
+	for i, column := range header {
+		if column == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Number of records returned so far, not counting the header
+func (seq *CSVSeq) Index() int {
+	return seq.index
+}
+
+// Return the next record
+func (seq *CSVSeq) Next() (*[]string, error) {
+	if err := seq.start(); err != nil {
+		seq.lastErr = err
+		return nil, err
+	}
+	record, err := seq.read()
+	seq.lastErr = err
+	if err != nil {
+		return nil, err
+	}
+	seq.index++
+	return &record, nil
+}
+
+// Seq of the records of a CSV file decoded into structs, matching header columns to struct fields.
+//
+// Add-ons: HasErr, HasIter, HasPosition
+//
+// A field matches the column named in its `csv` tag, eg `csv:"popularity"`, or without a tag, a column
+// with the same name ignoring case. Fields tagged `csv:"-"`, unexported fields and fields without a
+// matching column are left alone. Fields can be strings, bools, integers, floats, or types that
+// implement encoding.TextUnmarshaler; an empty column gives the zero value. A column that can't be
+// converted gives a *CSVError with the line number and column name.
+type CSVStructSeq[T any] struct {
+	*HasErr
+	*HasIter[*T]
+	*HasPosition
+	records *CSVSeq
+	fields  []int
+}
+
+// C'tor function. The input needs column names, from a header row (HasHeader) or the Header option.
+func NewCSVStructSeq[T any](rd io.Reader, opts CSVOptions) *CSVStructSeq[T] {
+	records := NewCSVSeq(rd, opts)
+	sq := &CSVStructSeq[T]{
+		HasErr:      NewHasErr(),
+		HasPosition: records.HasPosition,
+		records:     records,
+	}
+	// HasIter needs the Seq object so it needs special treatment
+	sq.HasIter = NewHasIter(sq)
+	return sq
+}
+
+// Return the column names, as for CSVSeq.Header()
+func (seq *CSVStructSeq[T]) Header() ([]string, error) {
+	return seq.records.Header()
+}
+
+// Work out which column goes in each field of T
+func (seq *CSVStructSeq[T]) mapFields() error {
+	header, err := seq.records.Header()
+	if err != nil {
+		return err
+	}
+	if header == nil {
+		return ErrNoHeader
+	}
+	seq.fields, err = mapStructFields(reflect.TypeFor[T](), header, "csv")
+	return err
+}
+
+// Return the next record as a struct
+func (seq *CSVStructSeq[T]) Next() (*T, error) {
+	if seq.fields == nil {
+		if err := seq.mapFields(); err != nil {
+			seq.lastErr = err
+			return nil, err
+		}
+	}
+	record, err := seq.records.Next()
+	if err != nil {
+		seq.lastErr = err
+		return nil, err
+	}
+	val, i, err := decodeStruct[T](seq.fields, *record)
+	if err != nil {
+		header, _ := seq.records.Header()
+		seq.lastErr = &CSVError{seq.LastLocation().Line, header[i], err}
+		return nil, seq.lastErr
+	}
+	seq.lastErr = nil
+	return val, nil
+}
+
+// For each field of struct type typ, find the index of its column: the one named in its tag (eg
+// `csv:"name"`), or without a tag, the one with the same name ignoring case. -1 means the field is
+// left alone.
+func mapStructFields(typ reflect.Type, columns []string, tagKey string) ([]int, error) {
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("need a struct type, not %s", typ)
+	}
+	fields := make([]int, typ.NumField())
+	for i := range typ.NumField() {
+		fields[i] = -1
+		field := typ.Field(i)
+		tag := field.Tag.Get(tagKey)
+		if !field.IsExported() || tag == "-" {
+			continue
+		}
+		for j, column := range columns {
+			if (tag != "" && column == tag) || (tag == "" && strings.EqualFold(column, field.Name)) {
+				fields[i] = j
+				break
+			}
+		}
+	}
+	return fields, nil
+}
+
+// Decode a record into a new T, using fields from mapStructFields(). On error, also returns the index
+// of the column that couldn't be converted.
+func decodeStruct[T any](fields []int, record []string) (*T, int, error) {
+	val := new(T)
+	v := reflect.ValueOf(val).Elem()
+	for i, j := range fields {
+		if j < 0 || j >= len(record) {
+			continue
+		}
+		if err := setField(v.Field(i), record[j]); err != nil {
+			return nil, j, err
+		}
+	}
+	return val, 0, nil
+}
+
+// Convert s to the type of v and store it
+func setField(v reflect.Value, s string) error {
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+	if s == "" {
+		v.SetZero()
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported field type %s", v.Type())
+	}
+	return nil
+}
+
+// Random-access view of the records of a CSV file, along the lines of FileFlc. The file is scanned once,
+// on first use, to find where each record starts; after that GetRecord() seeks straight to the record.
+//
+// FileCSVCollection is also a FiniteLineCollection, where each "line" is a record re-encoded as a single
+// CSV line, so it can be used with GetRandomLine() and RandomLineSeq.
+type FileCSVCollection struct {
+	path      string
+	opts      CSVOptions
+	header    []string
+	offsets   []int
+	lines     []int
+	isIndexed bool
+}
+
+// C'tor function
+func NewFileCSVCollection(path string, opts CSVOptions) *FileCSVCollection {
+	return &FileCSVCollection{path: path, opts: opts}
+}
+
+// Scan the file for the offset and line number of each record
+func (flc *FileCSVCollection) index() error {
+	if flc.isIndexed {
+		return nil
+	}
+	f, err := os.Open(flc.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	sq := NewCSVSeq(f, flc.opts)
+	header, err := sq.Header()
+	if err != nil {
+		return err
+	}
+	offsets, lines := []int{}, []int{}
+	for {
+		off, line := sq.Position(), sq.Location().Line-1
+		_, err := sq.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		offsets, lines = append(offsets, off), append(lines, line)
+	}
+	flc.header, flc.offsets, flc.lines, flc.isIndexed = header, offsets, lines, true
+	return nil
+}
+
+// Return the column names, as for CSVSeq.Header()
+func (flc *FileCSVCollection) Header() ([]string, error) {
+	if err := flc.index(); err != nil {
+		return nil, err
+	}
+	return flc.header, nil
+}
+
+// Number of records, not counting the header
+func (flc *FileCSVCollection) Count() (int, error) {
+	if err := flc.index(); err != nil {
+		return 0, err
+	}
+	return len(flc.offsets), nil
+}
+
+// Return record i, counting from 0
+func (flc *FileCSVCollection) GetRecord(i int) (*[]string, error) {
+	if err := flc.index(); err != nil {
+		return nil, err
+	}
+	if i < 0 || i >= len(flc.offsets) {
+		return nil, fmt.Errorf("record %d out of range [0, %d)", i, len(flc.offsets))
+	}
+	f, err := os.Open(flc.path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if _, err := f.Seek(int64(flc.offsets[i]), io.SeekStart); err != nil {
+		return nil, err
+	}
+	opts := flc.opts
+	opts.HasHeader = false
+	sq := newCSVSeqAt(f, opts, flc.offsets[i], flc.lines[i])
+	if quoted, ok := sq.rdr.(*quotedReader); ok {
+		quoted.rdr.FieldsPerRecord = len(flc.header)
+	}
+	return sq.Next()
+}
+
+// Return record i as a line of CSV
+func (flc *FileCSVCollection) GetLine(i int) (string, error) {
+	record, err := flc.GetRecord(i)
+	if err != nil {
+		return "", err
+	}
+	if flc.opts.NoQuotes {
+		return strings.Join(*record, string(flc.comma())), nil
+	}
+	var b bytes.Buffer
+	wr := csv.NewWriter(&b)
+	wr.Comma = flc.comma()
+	wr.Write(*record)
+	wr.Flush()
+	return strings.TrimSuffix(b.String(), "\n"), wr.Error()
+}
+
+func (flc *FileCSVCollection) comma() rune {
+	if flc.opts.Comma == 0 {
+		return ','
+	}
+	return flc.opts.Comma
+}
@@ -0,0 +1,151 @@
+package seq
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const petsCSV = `name,gender,popularity
+# Popularity is out of 100
+Rex,M,80
+"Fido, Jr.",M,55
+Zoë,F,
+"Spot
+the Dog",M,12
+`
+
+type pet struct {
+	Name       string
+	Gender     string `csv:"gender"`
+	Popularity int    `csv:"popularity"`
+	Ignored    string `csv:"-"`
+}
+
+func csvOpts() CSVOptions {
+	return CSVOptions{Comment: '#', HasHeader: true}
+}
+
+func TestCSVSeq(t *testing.T) {
+	sq := NewCSVSeq(strings.NewReader(petsCSV), csvOpts())
+	header, err := sq.Header()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"name", "gender", "popularity"}, header)
+	assert.Equal(t, 2, sq.Column("popularity"))
+	assert.Equal(t, -1, sq.Column("age"))
+	record, err := sq.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Rex", "M", "80"}, *record)
+	assert.Equal(t, 3, sq.LastLocation().Line)
+	record, err = sq.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Fido, Jr.", "M", "55"}, *record)
+	sq.Next()
+	record, err = sq.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Spot\nthe Dog", "M", "12"}, *record)
+	assert.Equal(t, 6, sq.LastLocation().Line)
+	assert.Equal(t, 8, sq.Location().Line)
+	assert.Equal(t, len(petsCSV), sq.Position())
+	assert.Equal(t, 4, sq.Index())
+	record, err = sq.Next()
+	assert.Nil(t, record)
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestCSVSeqWhere(t *testing.T) {
+	sq := NewCSVSeq(strings.NewReader(petsCSV), csvOpts())
+	gender := sq.Column("gender")
+	males := Where[*[]string](sq, func(record *[]string) bool { return (*record)[gender] == "M" })
+	names := []string{}
+	for record := range Iter(Limit(Skip(males, 1), 2)) {
+		names = append(names, (*record)[0])
+	}
+	assert.Equal(t, []string{"Fido, Jr.", "Spot\nthe Dog"}, names)
+}
+
+func TestCSVSeqTSV(t *testing.T) {
+	input := "Rex\tM\t\"80\"\r\n\r\nFido\tM\n"
+	sq := NewCSVSeq(strings.NewReader(input), CSVOptions{Comma: '\t', NoQuotes: true, Header: []string{"name", "gender", "popularity"}})
+	record, err := sq.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Rex", "M", `"80"`}, *record)
+	assert.Equal(t, 1, sq.Column("gender"))
+	record, err = sq.Next()
+	assert.Nil(t, record)
+	var parseErr *csv.ParseError
+	assert.True(t, errors.As(err, &parseErr))
+	assert.Equal(t, 3, parseErr.Line)
+	assert.True(t, errors.Is(err, csv.ErrFieldCount))
+	testEof(t, sq)
+}
+
+func TestCSVSeqParseError(t *testing.T) {
+	sq := NewCSVSeq(strings.NewReader("a,b\n1,2\n3\n\"4,5\n"), CSVOptions{})
+	vals := []string{}
+	errLines := []int{}
+	for {
+		record, err := sq.Next()
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			errLines = append(errLines, parseErr.Line)
+			continue
+		}
+		if err != nil {
+			break
+		}
+		vals = append(vals, strings.Join(*record, ""))
+	}
+	assert.Equal(t, []string{"ab", "12"}, vals)
+	assert.Equal(t, []int{3, 4}, errLines)
+}
+
+func TestCSVStructSeq(t *testing.T) {
+	sq := NewCSVStructSeq[pet](strings.NewReader(petsCSV), csvOpts())
+	pets := collectSeq[*pet](t, sq)
+	assert.Len(t, pets, 4)
+	assert.Equal(t, pet{Name: "Fido, Jr.", Gender: "M", Popularity: 55}, *pets[1])
+	assert.Equal(t, pet{Name: "Zoë", Gender: "F"}, *pets[2])
+}
+
+func TestCSVStructSeqErrors(t *testing.T) {
+	sq := NewCSVStructSeq[pet](strings.NewReader("name,popularity\nRex,lots\nFido,3\n"), CSVOptions{HasHeader: true})
+	p, err := sq.Next()
+	assert.Nil(t, p)
+	var csvErr *CSVError
+	assert.True(t, errors.As(err, &csvErr))
+	assert.Equal(t, `line 2, column "popularity": strconv.ParseInt: parsing "lots": invalid syntax`, err.Error())
+	p, err = sq.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, pet{Name: "Fido", Popularity: 3}, *p)
+
+	sq = NewCSVStructSeq[pet](strings.NewReader("Rex,M,3\n"), CSVOptions{})
+	_, err = sq.Next()
+	assert.Equal(t, ErrNoHeader, err)
+}
+
+func TestFileCSVCollection(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pets.csv")
+	assert.NoError(t, os.WriteFile(path, []byte(petsCSV), 0o644))
+	flc := NewFileCSVCollection(path, csvOpts())
+	n, err := flc.Count()
+	assert.NoError(t, err)
+	assert.Equal(t, 4, n)
+	record, err := flc.GetRecord(3)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Spot\nthe Dog", "M", "12"}, *record)
+	line, err := flc.GetLine(1)
+	assert.NoError(t, err)
+	assert.Equal(t, `"Fido, Jr.",M,55`, line)
+	_, err = flc.GetRecord(4)
+	assert.Error(t, err)
+	line, err = GetRandomLine(flc)
+	assert.NoError(t, err)
+	assert.Contains(t, []string{"Rex,M,80", `"Fido, Jr.",M,55`, "Zoë,F,", "\"Spot\nthe Dog\",M,12"}, line)
+}
@@ -0,0 +1,196 @@
+package seq
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+)
+
+// KeyFunc maps an element to the key used to compare it with other elements. Used by DistinctBy() and
+// friends, eg to dedupe names case-insensitively with strings.ToLower.
+type KeyFunc[T comparable, K comparable] func(T) K
+
+type seqDistinctBy[T comparable, K comparable] struct {
+	*HasErr
+	sqInner Seq[T]
+	keyFn   KeyFunc[T, K]
+	seen    map[K]struct{}
+	dropped int
+}
+
+func NewSeqDistinctByWrapper[T comparable, K comparable](sqInner Seq[T], keyFn KeyFunc[T, K]) *seqDistinctBy[T, K] {
+	return &seqDistinctBy[T, K]{NewHasErr(), sqInner, keyFn, map[K]struct{}{}, 0}
+}
+
+// Return the next element whose key hasn't been seen before. Every key ever returned is remembered, so
+// memory grows with the number of distinct elements. See DistinctBloom() for unbounded streams.
+func (sq *seqDistinctBy[T, K]) Next() (T, error) {
+	for {
+		next, err := sq.sqInner.Next()
+		if next == *new(T) && err != nil {
+			sq.lastErr = err
+			return next, err
+		}
+		key := sq.keyFn(next)
+		if _, hasKey := sq.seen[key]; !hasKey {
+			sq.seen[key] = struct{}{}
+			sq.lastErr = err
+			return next, err
+		}
+		sq.dropped++
+		// The duplicate came with an error (eg a final element + io.EOF), so stop here
+		if err != nil {
+			sq.lastErr = err
+			return *new(T), err
+		}
+	}
+}
+
+// Number of duplicate elements that have been skipped
+func (sq *seqDistinctBy[T, K]) Dropped() int {
+	return sq.dropped
+}
+
+// Return a Seq that skips elements whose key (as returned by keyFn) has already been returned
+func DistinctBy[T comparable, K comparable](sqInner Seq[T], keyFn KeyFunc[T, K]) *seqDistinctBy[T, K] {
+	return NewSeqDistinctByWrapper(sqInner, keyFn)
+}
+
+// Return a Seq that skips elements that have already been returned
+func Distinct[T comparable](sqInner Seq[T]) *seqDistinctBy[T, T] {
+	return NewSeqDistinctByWrapper(sqInner, func(t T) T { return t })
+}
+
+type seqDedupeAdjacent[T comparable] struct {
+	*HasErr
+	sqInner Seq[T]
+	last    T
+	hasLast bool
+	dropped int
+}
+
+func NewSeqDedupeAdjacentWrapper[T comparable](sqInner Seq[T]) *seqDedupeAdjacent[T] {
+	return &seqDedupeAdjacent[T]{NewHasErr(), sqInner, *new(T), false, 0}
+}
+
+// Return the next element that differs from the one before it. Only the previous element is remembered,
+// so memory use is constant.
+func (sq *seqDedupeAdjacent[T]) Next() (T, error) {
+	for {
+		next, err := sq.sqInner.Next()
+		if next == *new(T) && err != nil {
+			sq.lastErr = err
+			return next, err
+		}
+		if !sq.hasLast || next != sq.last {
+			sq.last = next
+			sq.hasLast = true
+			sq.lastErr = err
+			return next, err
+		}
+		sq.dropped++
+		if err != nil {
+			sq.lastErr = err
+			return *new(T), err
+		}
+	}
+}
+
+// Number of duplicate elements that have been skipped
+func (sq *seqDedupeAdjacent[T]) Dropped() int {
+	return sq.dropped
+}
+
+// Return a Seq that collapses runs of equal elements into a single element, like `uniq`
+func DedupeAdjacent[T comparable](sqInner Seq[T]) *seqDedupeAdjacent[T] {
+	return NewSeqDedupeAdjacentWrapper(sqInner)
+}
+
+// Minimal Bloom filter for DistinctBloom(). Uses double hashing on a single 64-bit FNV-1a hash of the
+// element's %#v representation, so it works for any comparable type without a user-supplied hash function.
+type bloomFilter struct {
+	bits []uint64
+	m    uint64
+	k    uint64
+}
+
+// Size the filter for n elements and a false positive rate of p
+func newBloomFilter(n int, p float64) *bloomFilter {
+	if n < 1 {
+		n = 1
+	}
+	if p <= 0 || p >= 1 {
+		p = 0.01
+	}
+	m := uint64(math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2)))
+	if m < 64 {
+		m = 64
+	}
+	k := uint64(math.Round(float64(m) / float64(n) * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+	return &bloomFilter{make([]uint64, (m+63)/64), m, k}
+}
+
+// Add v to the filter, and return true if v was (probably) already present
+func (bf *bloomFilter) testAndAdd(v any) bool {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%#v", v)
+	sum := h.Sum64()
+	h1, h2 := sum&0xffffffff, sum>>32
+	present := true
+	for i := range bf.k {
+		bit := (h1 + i*h2) % bf.m
+		word, mask := bit/64, uint64(1)<<(bit%64)
+		if bf.bits[word]&mask == 0 {
+			present = false
+			bf.bits[word] |= mask
+		}
+	}
+	return present
+}
+
+type seqDistinctBloom[T comparable] struct {
+	*HasErr
+	sqInner Seq[T]
+	filter  *bloomFilter
+	dropped int
+}
+
+func NewSeqDistinctBloomWrapper[T comparable](sqInner Seq[T], expectedN int, fpRate float64) *seqDistinctBloom[T] {
+	return &seqDistinctBloom[T]{NewHasErr(), sqInner, newBloomFilter(expectedN, fpRate), 0}
+}
+
+// Return the next element that (probably) hasn't been seen before. A false positive means a new element
+// is occasionally dropped; duplicates are never returned.
+func (sq *seqDistinctBloom[T]) Next() (T, error) {
+	for {
+		next, err := sq.sqInner.Next()
+		if next == *new(T) && err != nil {
+			sq.lastErr = err
+			return next, err
+		}
+		if !sq.filter.testAndAdd(next) {
+			sq.lastErr = err
+			return next, err
+		}
+		sq.dropped++
+		if err != nil {
+			sq.lastErr = err
+			return *new(T), err
+		}
+	}
+}
+
+// Number of elements that have been skipped, including any false positives
+func (sq *seqDistinctBloom[T]) Dropped() int {
+	return sq.dropped
+}
+
+// Return a Seq that approximately skips elements that have already been returned, using a Bloom filter
+// sized for expectedN distinct elements at a false positive rate of fpRate. Memory is fixed up front, which
+// makes this suitable for streams too big to remember exactly.
+func DistinctBloom[T comparable](sqInner Seq[T], expectedN int, fpRate float64) *seqDistinctBloom[T] {
+	return NewSeqDistinctBloomWrapper(sqInner, expectedN, fpRate)
+}
@@ -0,0 +1,96 @@
+package seq
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistinctSimple(t *testing.T) {
+	rd := strings.NewReader("a\nb\na\nc\nb\n")
+	sqDistinct := Distinct(NewLineSeq(rd))
+	var (val string; err error)
+	val, err = sqDistinct.Next()
+	testNextOk(t, "a", val, err)
+	val, err = sqDistinct.Next()
+	testNextOk(t, "b", val, err)
+	val, err = sqDistinct.Next()
+	testNextOk(t, "c", val, err)
+	val, err = sqDistinct.Next()
+	testNextEof(t, val, err)
+	assert.Equal(t, 2, sqDistinct.Dropped())
+	testEof(t, sqDistinct)
+}
+
+func TestDistinctByLower(t *testing.T) {
+	rd := strings.NewReader("Rex\nREX\nFido\nrex\nfido\n")
+	sqDistinct := DistinctBy(NewLineSeq(rd), strings.ToLower)
+	names := []string{}
+	for name := range Iter(sqDistinct) {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	assert.Equal(t, []string{"Rex", "Fido"}, names)
+	assert.Equal(t, 3, sqDistinct.Dropped())
+}
+
+func TestDistinctLimitDuplicateAtEof(t *testing.T) {
+	// Limit returns its last element together with io.EOF. If that element is a duplicate, Distinct should
+	// return ("", EOF) rather than carry on reading.
+	rd := strings.NewReader("a\na\nb\n")
+	sq := Distinct(Limit(NewLineSeq(rd), 2))
+	var (val string; err error)
+	val, err = sq.Next()
+	testNextOk(t, "a", val, err)
+	val, err = sq.Next()
+	testNextEof(t, val, err)
+}
+
+func TestDedupeAdjacent(t *testing.T) {
+	rd := strings.NewReader("a\na\nb\nb\nb\na\n")
+	sq := DedupeAdjacent(NewLineSeq(rd))
+	var (val string; err error)
+	val, err = sq.Next()
+	testNextOk(t, "a", val, err)
+	val, err = sq.Next()
+	testNextOk(t, "b", val, err)
+	val, err = sq.Next()
+	testNextOk(t, "a", val, err)
+	val, err = sq.Next()
+	testNextEof(t, val, err)
+	assert.Equal(t, 3, sq.Dropped())
+}
+
+func TestDistinctBloomPetnames(t *testing.T) {
+	f, err := os.Open("./petnames.txt")
+	assert.Nil(t, err)
+	// petnames.txt has 1000 unique names, so no duplicates should be returned and few (if any) should be lost
+	sq := DistinctBloom(NewLineSeq(f), 1000, 0.001)
+	n, err := Count(sq)
+	assert.Nil(t, err)
+	assert.Equal(t, 1000, n+sq.Dropped())
+	assert.LessOrEqual(t, sq.Dropped(), 10)
+}
+
+func TestDistinctBloomDuplicates(t *testing.T) {
+	b := strings.Builder{}
+	for i := range 500 {
+		fmt.Fprintf(&b, "%d\n%d\n", i, i)
+	}
+	sq := DistinctBloom(NewLineSeq(strings.NewReader(b.String())), 500, 0.01)
+	seen := map[string]struct{}{}
+	for val := range Iter(sq) {
+		if val == "" {
+			continue
+		}
+		_, hasKey := seen[val]
+		assert.False(t, hasKey, val)
+		seen[val] = struct{}{}
+	}
+	assert.Equal(t, 1000, len(seen)+sq.Dropped())
+	assert.GreaterOrEqual(t, sq.Dropped(), 500)
+}
@@ -0,0 +1,261 @@
+package seq
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Text encoding of a data source, for DecodingReader and the sequences built on it
+type Encoding int
+
+const (
+	// No decoding: bytes are passed through as-is, and assumed to be UTF-8. This is the default.
+	EncodingRaw Encoding = iota
+	// Detect the encoding from the byte order mark (BOM), falling back to UTF-8 if there isn't one
+	EncodingAuto
+	EncodingUTF8
+	EncodingUTF16LE
+	EncodingUTF16BE
+	// ISO-8859-1. Every byte is a character, so there's no BOM and no invalid input.
+	EncodingLatin1
+)
+
+func (enc Encoding) String() string {
+	switch enc {
+	case EncodingAuto:
+		return "auto"
+	case EncodingUTF8:
+		return "UTF-8"
+	case EncodingUTF16LE:
+		return "UTF-16LE"
+	case EncodingUTF16BE:
+		return "UTF-16BE"
+	case EncodingLatin1:
+		return "ISO-8859-1"
+	default:
+		return "raw"
+	}
+}
+
+var (
+	bomUTF8    = []byte{0xef, 0xbb, 0xbf}
+	bomUTF16LE = []byte{0xff, 0xfe}
+	bomUTF16BE = []byte{0xfe, 0xff}
+)
+
+// Size of the chunks DecodingReader reads from its source
+const decodeBufSize = 4096
+
+// Reader that converts text in another encoding to UTF-8, so it can be used with RuneSeq, LineSeq and
+// anything else that expects UTF-8. A BOM at the start of the input is used to detect the encoding (for
+// EncodingAuto) and is always stripped. Sniffing happens on the first Read(), or the first call to
+// Encoding(), whichever comes first.
+//
+// Malformed input (an odd trailing byte or an unpaired surrogate in UTF-16) is replaced with
+// utf8.RuneError (U+FFFD) and counted by InvalidCount(); Read() doesn't fail on it. A LineSeq or RuneSeq
+// with an Encoding applies its InvalidUTF8 policy to these replacements, and InvalidUTF8Fail gives a
+// *DecodeError with the offset of the malformed bytes in the encoded input.
+type DecodingReader struct {
+	src       *bufio.Reader
+	enc       Encoding
+	isSniffed bool
+	in        []byte
+	nIn       int
+	out       []byte
+	srcErr    error
+	nSrc      int
+	nOut      int
+	nInvalid  int
+	isTracked bool
+	malformed []malformedInput
+}
+
+// Malformed input, and the offset of the U+FFFD that replaced it in the decoded output
+type malformedInput struct {
+	outOff int
+	err    *DecodeError
+}
+
+// Error for malformed input in a DecodingReader, with the byte offset in the encoded input where it
+// starts and the offending bytes. It matches ErrInvalidUTF8 with errors.Is(), so it's handled like
+// invalid UTF-8 by code that checks for that.
+type DecodeError struct {
+	Encoding Encoding
+	Offset   int
+	Bytes    []byte
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("malformed %s at byte offset %d: % x", e.Encoding, e.Offset, e.Bytes)
+}
+
+func (e *DecodeError) Is(target error) bool {
+	return target == ErrInvalidUTF8
+}
+
+// C'tor function. Pass EncodingAuto to sniff the BOM, or a specific encoding if it's known.
+func NewDecodingReader(rd io.Reader, enc Encoding) *DecodingReader {
+	return &DecodingReader{
+		src: bufio.NewReader(rd),
+		enc: enc,
+		in:  make([]byte, decodeBufSize),
+	}
+}
+
+// Return the encoding being decoded. For EncodingAuto this is the detected encoding, which may mean
+// reading the first few bytes of the source.
+func (d *DecodingReader) Encoding() Encoding {
+	d.sniff()
+	return d.enc
+}
+
+// Check for a BOM, skip it, and settle on an encoding
+func (d *DecodingReader) sniff() {
+	if d.isSniffed {
+		return
+	}
+	d.isSniffed = true
+	if d.enc == EncodingRaw || d.enc == EncodingLatin1 {
+		return
+	}
+	peek, _ := d.src.Peek(len(bomUTF8))
+	detected, bomLen := EncodingAuto, 0
+	switch {
+	case bytes.HasPrefix(peek, bomUTF8):
+		detected, bomLen = EncodingUTF8, len(bomUTF8)
+	case bytes.HasPrefix(peek, bomUTF16LE):
+		detected, bomLen = EncodingUTF16LE, len(bomUTF16LE)
+	case bytes.HasPrefix(peek, bomUTF16BE):
+		detected, bomLen = EncodingUTF16BE, len(bomUTF16BE)
+	}
+	if d.enc == EncodingAuto {
+		d.enc = EncodingUTF8
+		if detected != EncodingAuto {
+			d.enc = detected
+		}
+	}
+	// Only strip a BOM that matches the encoding; anything else is data
+	if detected == d.enc {
+		d.src.Discard(bomLen)
+		d.nSrc = bomLen
+	}
+}
+
+// Number of malformed sequences replaced with U+FFFD so far
+func (d *DecodingReader) InvalidCount() int {
+	return d.nInvalid
+}
+
+// Remove and return the errors for malformed input whose U+FFFD is between start and end in the decoded
+// output. Anything before start has been skipped by the caller, and is dropped.
+func (d *DecodingReader) takeMalformed(start int, end int) []*DecodeError {
+	var errs []*DecodeError
+	i := 0
+	for ; i < len(d.malformed) && d.malformed[i].outOff < end; i++ {
+		if d.malformed[i].outOff >= start {
+			errs = append(errs, d.malformed[i].err)
+		}
+	}
+	d.malformed = d.malformed[i:]
+	return errs
+}
+
+// Read decoded UTF-8 text
+func (d *DecodingReader) Read(p []byte) (int, error) {
+	d.sniff()
+	if d.enc == EncodingRaw || d.enc == EncodingUTF8 {
+		n, err := d.src.Read(p)
+		d.nOut += n
+		return n, err
+	}
+	for len(d.out) == 0 {
+		if d.srcErr != nil {
+			// Decode whatever was left over, eg an odd trailing byte
+			if d.nIn > 0 {
+				d.decode(true)
+				continue
+			}
+			return 0, d.srcErr
+		}
+		n, err := d.src.Read(d.in[d.nIn:])
+		d.nIn += n
+		d.srcErr = err
+		d.decode(false)
+	}
+	n := copy(p, d.out)
+	d.out = d.out[n:]
+	d.nOut += n
+	return n, nil
+}
+
+// Convert the raw bytes in d.in to UTF-8 in d.out, keeping any incomplete sequence for next time
+func (d *DecodingReader) decode(atEOF bool) {
+	in := d.in[:d.nIn]
+	out := d.out[:0]
+	var consumed int
+	if d.enc == EncodingLatin1 {
+		for _, b := range in {
+			out = utf8.AppendRune(out, rune(b))
+		}
+		consumed = len(in)
+	} else {
+		var order binary.ByteOrder = binary.LittleEndian
+		if d.enc == EncodingUTF16BE {
+			order = binary.BigEndian
+		}
+		out, consumed = d.decodeUTF16(out, in, order, atEOF)
+	}
+	d.out = out
+	d.nSrc += consumed
+	d.nIn = copy(d.in, in[consumed:])
+}
+
+// Append U+FFFD to out in place of the malformed input in[i:i+n], and count it. If a LineSeq or RuneSeq
+// will want it, keep an error for it too.
+func (d *DecodingReader) appendMalformed(out []byte, in []byte, i int, n int) []byte {
+	d.nInvalid++
+	if d.isTracked {
+		err := &DecodeError{d.enc, d.nSrc + i, bytes.Clone(in[i : i+n])}
+		d.malformed = append(d.malformed, malformedInput{d.nOut + len(out), err})
+	}
+	return utf8.AppendRune(out, utf8.RuneError)
+}
+
+// Append the UTF-8 encoding of the UTF-16 text in `in` to out. Returns the new out and the number of
+// bytes of `in` used; unless atEOF, a trailing odd byte or high surrogate is left for the next call.
+func (d *DecodingReader) decodeUTF16(out []byte, in []byte, order binary.ByteOrder, atEOF bool) ([]byte, int) {
+	i := 0
+	for i+1 < len(in) {
+		u := rune(order.Uint16(in[i:]))
+		if !utf16.IsSurrogate(u) {
+			out = utf8.AppendRune(out, u)
+			i += 2
+			continue
+		}
+		// High surrogate: needs a low surrogate after it
+		if u < 0xdc00 {
+			if i+3 >= len(in) && !atEOF {
+				return out, i
+			}
+			if i+3 < len(in) {
+				if ru := utf16.DecodeRune(u, rune(order.Uint16(in[i+2:]))); ru != utf8.RuneError {
+					out = utf8.AppendRune(out, ru)
+					i += 4
+					continue
+				}
+			}
+		}
+		out = d.appendMalformed(out, in, i, 2)
+		i += 2
+	}
+	if atEOF && i < len(in) {
+		out = d.appendMalformed(out, in, i, len(in)-i)
+		i = len(in)
+	}
+	return out, i
+}
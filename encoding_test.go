@@ -0,0 +1,143 @@
+package seq
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/iotest"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Encode str as UTF-16 with a BOM, the way spreadsheets export it
+func encodeUTF16(str string, order binary.AppendByteOrder) []byte {
+	b := []byte{}
+	b = order.AppendUint16(b, 0xfeff)
+	for _, u := range utf16.Encode([]rune(str)) {
+		b = order.AppendUint16(b, u)
+	}
+	return b
+}
+
+func TestDecodingReaderSniff(t *testing.T) {
+	str := "Zoë\r\nRex 🐕\r\n"
+	tests := []struct {
+		data     []byte
+		expected Encoding
+	}{
+		{encodeUTF16(str, binary.LittleEndian), EncodingUTF16LE},
+		{encodeUTF16(str, binary.BigEndian), EncodingUTF16BE},
+		{append([]byte{0xef, 0xbb, 0xbf}, str...), EncodingUTF8},
+		{[]byte(str), EncodingUTF8},
+	}
+	for _, test := range tests {
+		d := NewDecodingReader(bytes.NewReader(test.data), EncodingAuto)
+		assert.Equal(t, test.expected, d.Encoding())
+		decoded, err := io.ReadAll(d)
+		assert.Nil(t, err)
+		assert.Equal(t, str, string(decoded))
+	}
+}
+
+func TestDecodingReaderOneByte(t *testing.T) {
+	// Surrogate pairs split across reads
+	str := "🐕🐈 names"
+	d := NewDecodingReader(iotest.OneByteReader(bytes.NewReader(encodeUTF16(str, binary.LittleEndian))), EncodingAuto)
+	decoded, err := io.ReadAll(d)
+	assert.Nil(t, err)
+	assert.Equal(t, str, string(decoded))
+}
+
+func TestDecodingReaderMalformedUTF16(t *testing.T) {
+	// Lone low surrogate, then a trailing odd byte
+	data := []byte{'a', 0, 0x00, 0xdc, 'b', 0, 'c'}
+	decoded, err := io.ReadAll(NewDecodingReader(bytes.NewReader(data), EncodingUTF16LE))
+	assert.Nil(t, err)
+	assert.Equal(t, "a�b�", string(decoded))
+}
+
+func TestLineSeqMalformedUTF16(t *testing.T) {
+	// BOM, an unpaired high surrogate, then "A\n", and a good line after it
+	data := []byte{0xff, 0xfe, 0x00, 0xd8, 'A', 0, '\n', 0, 'B', 0, '\n', 0}
+	// Pass through
+	sq := NewLineSeqWithOptions(bytes.NewReader(data), LineSeqOptions{Encoding: EncodingAuto})
+	assert.Equal(t, []string{"�A", "B"}, collectSeq[string](t, sq))
+	// Replace
+	sq = NewLineSeqWithOptions(bytes.NewReader(data), LineSeqOptions{Encoding: EncodingAuto, InvalidUTF8: InvalidUTF8Replace})
+	assert.Equal(t, []string{"�A", "B"}, collectSeq[string](t, sq))
+	assert.Equal(t, 1, sq.InvalidCount())
+	// Fail
+	sq = NewLineSeqWithOptions(bytes.NewReader(data), LineSeqOptions{Encoding: EncodingAuto, InvalidUTF8: InvalidUTF8Fail})
+	line, err := sq.Next()
+	assert.Equal(t, "", line)
+	assert.True(t, errors.Is(err, ErrInvalidUTF8))
+	var decodeErr *DecodeError
+	assert.True(t, errors.As(err, &decodeErr))
+	assert.Equal(t, 2, decodeErr.Offset)
+	assert.Equal(t, []byte{0x00, 0xd8}, decodeErr.Bytes)
+	assert.Equal(t, "malformed UTF-16LE at byte offset 2: 00 d8", err.Error())
+	assert.Equal(t, 1, sq.InvalidCount())
+	line, err = sq.Next()
+	testNextOk(t, "B", line, err)
+	assert.Equal(t, 1, sq.Index())
+}
+
+func TestRuneSeqMalformedUTF16(t *testing.T) {
+	// A real U+FFFD, then a lone low surrogate
+	data := []byte{0xfd, 0xff, 0x00, 0xdc, 'a', 0}
+	sq := NewRuneSeqEncoding(bytes.NewReader(data), EncodingUTF16LE).SetInvalidUTF8Policy(InvalidUTF8Fail)
+	ru, err := sq.Next()
+	testNextOk(t, utf8.RuneError, ru, err)
+	_, err = sq.Next()
+	var decodeErr *DecodeError
+	assert.True(t, errors.As(err, &decodeErr))
+	assert.Equal(t, 2, decodeErr.Offset)
+	ru, err = sq.Next()
+	testNextOk(t, 'a', ru, err)
+	assert.Equal(t, 1, sq.InvalidCount())
+	assert.Equal(t, 2, sq.Index())
+}
+
+func TestDecodingReaderLatin1(t *testing.T) {
+	data := []byte{'Z', 'o', 0xeb, '\n'}
+	sq := NewLineSeqWithOptions(bytes.NewReader(data), LineSeqOptions{Encoding: EncodingLatin1})
+	line, err := sq.Next()
+	testNextOk(t, "Zoë", line, err)
+	assert.Equal(t, EncodingLatin1, sq.Encoding())
+	assert.True(t, utf8.ValidString(line))
+}
+
+func TestLineSeqUTF16(t *testing.T) {
+	data := encodeUTF16("Zoë\r\nRex\r\n", binary.LittleEndian)
+	sq := NewLineSeqWithOptions(bytes.NewReader(data), LineSeqOptions{Encoding: EncodingAuto, LineEnding: LineEndingCRLF})
+	assert.Equal(t, []string{"Zoë", "Rex"}, collectSeq[string](t, sq))
+	assert.Equal(t, EncodingUTF16LE, sq.Encoding())
+	// Without decoding it's garbage
+	assert.Equal(t, EncodingRaw, NewLineSeq(bytes.NewReader(data)).Encoding())
+}
+
+func TestRuneSeqEncoding(t *testing.T) {
+	sq := NewRuneSeqEncoding(bytes.NewReader(encodeUTF16("Zoë", binary.BigEndian)), EncodingAuto)
+	assert.Equal(t, []rune{'Z', 'o', 'ë'}, collectSeq[rune](t, sq))
+	assert.Equal(t, EncodingUTF16BE, sq.Encoding())
+	assert.Equal(t, "UTF-16BE", sq.Encoding().String())
+}
+
+func TestFileFlcUTF16(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "names.txt")
+	err := os.WriteFile(path, encodeUTF16("Rex\r\nFido\r\nZoë\r\n", binary.LittleEndian), 0644)
+	assert.Nil(t, err)
+	flc := NewFileFlcWithOptions(path, LineSeqOptions{Encoding: EncodingAuto, LineEnding: LineEndingCRLF})
+	n, err := flc.Count()
+	assert.Nil(t, err)
+	assert.Equal(t, 3, n)
+	line, err := flc.GetLine(2)
+	assert.Nil(t, err)
+	assert.Equal(t, "Zoë", line)
+}
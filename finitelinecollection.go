@@ -22,13 +22,17 @@ func GetRandomLine(flc FiniteLineCollection) (string, error) {
 	return line, err
 }
 
+// FiniteLineCollection backed by a slice of strings. It is also a Seq[string], returning its lines in
+// index order; Next() has its own cursor and doesn't affect GetLine().
 type ArrayFiniteLineCollection struct {
 	lines []string
+	i     int
 }
 
 func NewArrayFiniteLineCollection(lines []string) *ArrayFiniteLineCollection {
 	return &ArrayFiniteLineCollection{
 		lines,
+		0,
 	}
 }
 
@@ -40,33 +44,217 @@ func (flc *ArrayFiniteLineCollection) GetLine(i int) (string, error) {
 	return flc.lines[i], nil
 }
 
+func (flc *ArrayFiniteLineCollection) Next() (string, error) {
+	if flc.i >= len(flc.lines) {
+		return "", io.EOF
+	}
+	line := flc.lines[flc.i]
+	flc.i++
+	return line, nil
+}
+
+// How FileFlc finds lines for Count() and GetLine()
+type RandomAccess int
+
+const (
+	// Read the file from the start for every call. Nothing is kept between calls, but each call costs a
+	// full read (and decompression) of the file. This is the default.
+	RandomAccessRescan RandomAccess = iota
+	// Scan the file once and keep the offset of every 64th line, so that GetLine() can seek close to the
+	// line it wants. Compressed data can't be seeked into, so a file with a Compression (or Encoding)
+	// option is first decompressed (and decoded) into a temporary file, which Close() removes.
+	RandomAccessIndex
+)
+
+// With RandomAccessIndex, FileFlc keeps the offset of every flcIndexInterval-th line
+const flcIndexInterval = 64
+
+// FiniteLineCollection backed by a file. By default the file is reopened and read with a LineSeq for
+// each call; see SetRandomAccess() for an alternative for large or compressed files.
+//
+// Lines are counted the way LineSeq returns them: a last line without a trailing newline counts, so
+// "a\nb" has 2 lines, as does "a\nb\n". (Before, Count() left such a line out, although GetLine() could
+// still return it.) Both access modes count the same way.
 type FileFlc struct {
-	path string
+	path      string
+	opts      LineSeqOptions
+	access    RandomAccess
+	isIndexed bool
+	indexPath string
+	offsets   []int64
+	nLines    int
+	tmpPath   string
 }
 
 func NewFileFlc(path string) *FileFlc {
-	return &FileFlc{path}
+	return &FileFlc{path: path}
+}
+
+// C'tor function for files that need LineSeq options, eg UTF-16 files:
+// `LineSeqOptions{Encoding: EncodingAuto}`, or compressed files:
+// `LineSeqOptions{Compression: CompressionAuto}`
+func NewFileFlcWithOptions(path string, opts LineSeqOptions) *FileFlc {
+	return &FileFlc{path: path, opts: opts}
+}
+
+// Set how lines are found, and return the FileFlc
+func (flc *FileFlc) SetRandomAccess(access RandomAccess) *FileFlc {
+	flc.access = access
+	return flc
+}
+
+// Remove the temporary file made by RandomAccessIndex, if any. The FileFlc can still be used; the
+// index is rebuilt if needed.
+func (flc *FileFlc) Close() error {
+	flc.isIndexed, flc.offsets, flc.nLines = false, nil, 0
+	if flc.tmpPath == "" {
+		return nil
+	}
+	err := os.Remove(flc.tmpPath)
+	flc.tmpPath = ""
+	return err
+}
+
+// Decompress and decode the file into a temporary file, and return its path
+func (flc *FileFlc) spill() (string, error) {
+	f, err := os.Open(flc.path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	var rd io.Reader = f
+	if flc.opts.Compression != CompressionNone {
+		rd = NewDecompressingReader(rd, flc.opts.Compression)
+	}
+	if flc.opts.Encoding != EncodingRaw {
+		rd = NewDecodingReader(rd, flc.opts.Encoding)
+	}
+	tmp, err := os.CreateTemp("", "seq-flc-*")
+	if err != nil {
+		return "", err
+	}
+	_, err = io.Copy(tmp, rd)
+	if errClose := tmp.Close(); err == nil {
+		err = errClose
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	return tmp.Name(), nil
+}
+
+// Whether the file really is compressed, which for CompressionAuto means taking a look
+func (flc *FileFlc) isCompressed() (bool, error) {
+	if flc.opts.Compression != CompressionAuto {
+		return flc.opts.Compression != CompressionNone, nil
+	}
+	f, err := os.Open(flc.path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	return NewDecompressingReader(f, CompressionAuto).Compression() != CompressionNone, nil
+}
+
+// Options for reading the file that the index refers to, which is already decompressed and decoded
+func (flc *FileFlc) indexOpts() LineSeqOptions {
+	opts := flc.opts
+	if flc.tmpPath != "" {
+		opts.Compression, opts.Encoding = CompressionNone, EncodingRaw
+	}
+	return opts
+}
+
+// Scan the file for line offsets, the first time through
+func (flc *FileFlc) buildIndex() error {
+	if flc.isIndexed {
+		return nil
+	}
+	flc.indexPath = flc.path
+	isCompressed, err := flc.isCompressed()
+	if err != nil {
+		return err
+	}
+	if isCompressed || flc.opts.Encoding != EncodingRaw {
+		tmpPath, err := flc.spill()
+		if err != nil {
+			return err
+		}
+		flc.tmpPath, flc.indexPath = tmpPath, tmpPath
+	}
+	f, err := os.Open(flc.indexPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	sq := NewLineSeqWithOptions(f, flc.indexOpts())
+	offsets := []int64{}
+	n := 0
+	for {
+		off := int64(sq.Position())
+		line, err := sq.NextBytes()
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		if isLine(line, err) {
+			if n%flcIndexInterval == 0 {
+				offsets = append(offsets, off)
+			}
+			n++
+		}
+		if err != nil {
+			break
+		}
+	}
+	flc.offsets, flc.nLines, flc.isIndexed = offsets, n, true
+	return nil
 }
 
 func (flc *FileFlc) Count() (int, error) {
+	if flc.access == RandomAccessIndex {
+		if err := flc.buildIndex(); err != nil {
+			return 0, err
+		}
+		return flc.nLines, nil
+	}
 	f, err := os.Open(flc.path)
 	if err != nil {
 		return 0, err
 	}
-	var seq Seq[string] = NewLineSeq(f)
-	n, err := Count(seq)
-	if errors.Is(err, io.EOF) {
-		err = nil
+	defer f.Close()
+	sq := NewLineSeqWithOptions(f, flc.opts)
+	n := 0
+	for {
+		line, err := sq.NextBytes()
+		if err != nil && !errors.Is(err, io.EOF) {
+			return 0, err
+		}
+		if isLine(line, err) {
+			n++
+		}
+		if err != nil {
+			return n, nil
+		}
 	}
-	return n, err
+}
+
+// Whether a result from LineSeq is a line, ie anything but the empty remainder at EOF. A last line
+// without a terminator counts, as it does for LineSeq.
+func isLine(line []byte, err error) bool {
+	return err == nil || len(line) > 0
 }
 
 func (flc *FileFlc) GetLine(n int) (string, error) {
+	if flc.access == RandomAccessIndex {
+		return flc.getIndexedLine(n)
+	}
 	f, err := os.Open(flc.path)
 	if err != nil {
 		return "", err
 	}
-	seq := NewLineSeq(f)
+	defer f.Close()
+	seq := NewLineSeqWithOptions(f, flc.opts)
 	var line string
 	for range n + 1 {
 		line, err = seq.Next()
@@ -76,3 +264,30 @@ func (flc *FileFlc) GetLine(n int) (string, error) {
 	}
 	return line, nil
 }
+
+// GetLine() for RandomAccessIndex: seek to the nearest indexed line, then read forward
+func (flc *FileFlc) getIndexedLine(n int) (string, error) {
+	if err := flc.buildIndex(); err != nil {
+		return "", err
+	}
+	if n < 0 || n >= flc.nLines {
+		return "", io.EOF
+	}
+	f, err := os.Open(flc.indexPath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := f.Seek(flc.offsets[n/flcIndexInterval], io.SeekStart); err != nil {
+		return "", err
+	}
+	seq := NewLineSeqWithOptions(f, flc.indexOpts())
+	var line string
+	for range n%flcIndexInterval + 1 {
+		line, err = seq.Next()
+		if err != nil && line == "" {
+			return "", err
+		}
+	}
+	return line, nil
+}
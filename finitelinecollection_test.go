@@ -54,6 +54,13 @@ func TestArrayFlcGetRandomLines(t *testing.T) {
 	}
 }
 
+func TestArrayFlcSeq(t *testing.T) {
+	lines := []string{"a", "c", "b"}
+	var sq Seq[string] = NewArrayFiniteLineCollection(lines)
+	assert.Equal(t, lines, collectSeq(t, sq))
+	testEof(t, sq)
+}
+
 func TestFileFlcCount(t *testing.T) {
 	path := "./petnames.txt"
 	fileFlc := NewFileFlc(path)
@@ -113,6 +120,29 @@ func TestFileFlcGetRandomLines(t *testing.T) {
 	}
 }
 
+func TestFileFlcNoFinalNewline(t *testing.T) {
+	path := writeTestFile(t, "ab.txt", []byte("a\nb"))
+	for _, access := range []RandomAccess{RandomAccessRescan, RandomAccessIndex} {
+		fileFlc := NewFileFlc(path).SetRandomAccess(access)
+		n, err := fileFlc.Count()
+		assert.Nil(t, err)
+		assert.Equal(t, 2, n)
+		line, err := fileFlc.GetLine(1)
+		assert.Nil(t, err)
+		assert.Equal(t, "b", line)
+		_, err = fileFlc.GetLine(2)
+		assert.Equal(t, io.EOF, err)
+		seen := map[string]bool{}
+		for range 100 {
+			line, err := GetRandomLine(fileFlc)
+			assert.Nil(t, err)
+			seen[line] = true
+		}
+		assert.Equal(t, map[string]bool{"a": true, "b": true}, seen)
+		assert.NoError(t, fileFlc.Close())
+	}
+}
+
 func TestRandomLineSeq0(t *testing.T) {
 	lines := []string{"a", "b", "c"}
 	var flc FiniteLineCollection = NewArrayFiniteLineCollection(lines)
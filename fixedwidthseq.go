@@ -0,0 +1,190 @@
+package seq
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// A column of a fixed-width record: Width bytes starting at byte offset Start (counting from 0)
+type FixedWidthColumn struct {
+	Name  string
+	Start int
+	Width int
+}
+
+// Options for NewFixedWidthSeq()
+type FixedWidthOptions struct {
+	// Length of each record in bytes, for files where records follow one another with no line breaks.
+	// 0 means each line is a record.
+	RecordLength int
+	// Keep the spaces that pad each column. By default they're trimmed from both ends.
+	NoTrim bool
+}
+
+// Error for a fixed-width record whose column couldn't be converted to the type of its struct field.
+// Offset is the byte offset of the start of the record; Line is its line number, or for records without
+// line breaks, its record number counting from 1.
+type FixedWidthError struct {
+	Line   int
+	Offset int
+	Column string
+	Err    error
+}
+
+func (e *FixedWidthError) Error() string {
+	return fmt.Sprintf("line %d (byte offset %d), column %q: %v", e.Line, e.Offset, e.Column, e.Err)
+}
+
+func (e *FixedWidthError) Unwrap() error {
+	return e.Err
+}
+
+// Seq of fixed-width records, as produced by mainframes and report generators, split into named
+// columns. Each record is a pointer to a map from column name to value.
+//
+// Add-ons: HasErr, HasIter, HasPosition
+//
+// Columns are measured in bytes. A column that runs past the end of a short record is cut short, or ""
+// if it starts past the end. Records are lines (read with a LineSeq, so "\r\n" line endings are
+// handled) unless RecordLength is set, in which case they're blocks of that many bytes (read with a
+// ChunkSeq). Either way, positions are those of the underlying Seq: LastLocation() is the start of the
+// record.
+type FixedWidthSeq struct {
+	*HasErr
+	*HasIter[*map[string]string]
+	*HasPosition
+	records Seq[string]
+	columns []FixedWidthColumn
+	opts    FixedWidthOptions
+}
+
+// C'tor function
+func NewFixedWidthSeq(rd io.Reader, columns []FixedWidthColumn, opts FixedWidthOptions) *FixedWidthSeq {
+	sq := &FixedWidthSeq{
+		HasErr:  NewHasErr(),
+		columns: columns,
+		opts:    opts,
+	}
+	if opts.RecordLength > 0 {
+		chunks := NewChunkSeq(rd, opts.RecordLength)
+		sq.HasPosition = chunks.HasPosition
+		sq.records = &chunkStrings{chunks}
+	} else {
+		lines := NewLineSeqWithOptions(rd, LineSeqOptions{LineEnding: LineEndingCRLF})
+		sq.HasPosition = lines.HasPosition
+		sq.records = lines
+	}
+	// HasIter needs the Seq object so it needs special treatment
+	sq.HasIter = NewHasIter(sq)
+	return sq
+}
+
+// Seq[string] of the chunks of a ChunkSeq
+type chunkStrings struct {
+	chunks *ChunkSeq
+}
+
+func (cs *chunkStrings) Next() (string, error) {
+	chunk, err := cs.chunks.Next()
+	if chunk == nil {
+		return "", err
+	}
+	return string(*chunk), err
+}
+
+// Read the next record and split it into column values, in the order of the columns
+func (seq *FixedWidthSeq) next() ([]string, error) {
+	record, err := seq.records.Next()
+	if err != nil && record == "" {
+		seq.lastErr = err
+		return nil, err
+	}
+	vals := make([]string, len(seq.columns))
+	for i, column := range seq.columns {
+		start := min(column.Start, len(record))
+		end := min(column.Start+column.Width, len(record))
+		vals[i] = record[start:end]
+		if !seq.opts.NoTrim {
+			vals[i] = strings.TrimSpace(vals[i])
+		}
+	}
+	seq.lastErr = nil
+	return vals, nil
+}
+
+// Return the next record
+func (seq *FixedWidthSeq) Next() (*map[string]string, error) {
+	vals, err := seq.next()
+	if err != nil {
+		return nil, err
+	}
+	record := make(map[string]string, len(vals))
+	for i, column := range seq.columns {
+		record[column.Name] = vals[i]
+	}
+	return &record, nil
+}
+
+// Number of the current record, for errors: its line, or for records without line breaks its index
+func (seq *FixedWidthSeq) recordNum() int {
+	if seq.opts.RecordLength > 0 {
+		return seq.LastPosition()/seq.opts.RecordLength + 1
+	}
+	return seq.LastLocation().Line
+}
+
+// Seq of fixed-width records decoded into structs, matching column names to struct fields the same way
+// as CSVStructSeq, but with a `fixed` tag, eg `fixed:"popularity"`.
+//
+// Add-ons: HasErr, HasIter, HasPosition
+//
+// A column that can't be converted to its field's type gives a *FixedWidthError, and the next call to
+// Next() carries on with the next record.
+type FixedWidthStructSeq[T any] struct {
+	*HasErr
+	*HasIter[*T]
+	*HasPosition
+	records *FixedWidthSeq
+	fields  []int
+	errMap  error
+}
+
+// C'tor function
+func NewFixedWidthStructSeq[T any](rd io.Reader, columns []FixedWidthColumn, opts FixedWidthOptions) *FixedWidthStructSeq[T] {
+	records := NewFixedWidthSeq(rd, columns, opts)
+	names := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = column.Name
+	}
+	sq := &FixedWidthStructSeq[T]{
+		HasErr:      NewHasErr(),
+		HasPosition: records.HasPosition,
+		records:     records,
+	}
+	sq.fields, sq.errMap = mapStructFields(reflect.TypeFor[T](), names, "fixed")
+	// HasIter needs the Seq object so it needs special treatment
+	sq.HasIter = NewHasIter(sq)
+	return sq
+}
+
+// Return the next record as a struct
+func (seq *FixedWidthStructSeq[T]) Next() (*T, error) {
+	if seq.errMap != nil {
+		seq.lastErr = seq.errMap
+		return nil, seq.errMap
+	}
+	vals, err := seq.records.next()
+	if err != nil {
+		seq.lastErr = err
+		return nil, err
+	}
+	val, i, err := decodeStruct[T](seq.fields, vals)
+	if err != nil {
+		seq.lastErr = &FixedWidthError{seq.records.recordNum(), seq.LastPosition(), seq.records.columns[i].Name, err}
+		return nil, seq.lastErr
+	}
+	seq.lastErr = nil
+	return val, nil
+}
@@ -0,0 +1,88 @@
+package seq
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var petColumns = []FixedWidthColumn{
+	{"name", 0, 10},
+	{"gender", 10, 1},
+	{"popularity", 11, 4},
+}
+
+const petsFixed = "Rex       M  80\r\n" +
+	"Fido      M   5\r\n" +
+	"Zoë      F\r\n"
+
+func TestFixedWidthSeq(t *testing.T) {
+	sq := NewFixedWidthSeq(strings.NewReader(petsFixed), petColumns, FixedWidthOptions{})
+	record, err := sq.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"name": "Rex", "gender": "M", "popularity": "80"}, *record)
+	sq.Next()
+	record, err = sq.Next()
+	assert.NoError(t, err)
+	// Columns are bytes, and "ë" is 2 of them. The short record has no popularity.
+	assert.Equal(t, map[string]string{"name": "Zoë", "gender": "F", "popularity": ""}, *record)
+	assert.Equal(t, "3:1", sq.LastLocation().String())
+	assert.Equal(t, 34, sq.LastPosition())
+	testEof(t, sq)
+}
+
+func TestFixedWidthSeqNoTrim(t *testing.T) {
+	sq := NewFixedWidthSeq(strings.NewReader(petsFixed), petColumns, FixedWidthOptions{NoTrim: true})
+	record, err := sq.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, "Rex       ", (*record)["name"])
+	assert.Equal(t, "  80", (*record)["popularity"])
+}
+
+func TestFixedWidthSeqRecordLength(t *testing.T) {
+	input := "Rex  M080Fido M005Spot M"
+	columns := []FixedWidthColumn{{"name", 0, 5}, {"gender", 5, 1}, {"popularity", 6, 3}}
+	sq := NewFixedWidthSeq(strings.NewReader(input), columns, FixedWidthOptions{RecordLength: 9})
+	names := []string{}
+	for record := range Iter(sq) {
+		names = append(names, (*record)["name"]+(*record)["popularity"])
+	}
+	assert.Equal(t, []string{"Rex080", "Fido005", "Spot"}, names)
+}
+
+func TestFixedWidthStructSeq(t *testing.T) {
+	type fixedPet struct {
+		Name       string
+		Gender     string
+		Popularity int `fixed:"popularity"`
+	}
+	sq := NewFixedWidthStructSeq[fixedPet](strings.NewReader(petsFixed), petColumns, FixedWidthOptions{})
+	pets := collectSeq[*fixedPet](t, sq)
+	assert.Len(t, pets, 3)
+	assert.Equal(t, fixedPet{"Fido", "M", 5}, *pets[1])
+	assert.Equal(t, fixedPet{"Zoë", "F", 0}, *pets[2])
+}
+
+func TestFixedWidthStructSeqError(t *testing.T) {
+	type fixedPet struct {
+		Name       string `fixed:"name"`
+		Popularity uint8  `fixed:"popularity"`
+	}
+	input := "Rex       M 300\nFido      M  12\n"
+	sq := NewFixedWidthStructSeq[fixedPet](strings.NewReader(input), petColumns, FixedWidthOptions{})
+	pet, err := sq.Next()
+	assert.Nil(t, pet)
+	var fixedErr *FixedWidthError
+	assert.True(t, errors.As(err, &fixedErr))
+	assert.True(t, errors.Is(err, strconv.ErrRange))
+	assert.Equal(t, `line 1 (byte offset 0), column "popularity": strconv.ParseUint: parsing "300": value out of range`, err.Error())
+	pet, err = sq.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, fixedPet{"Fido", 12}, *pet)
+
+	_, err = NewFixedWidthStructSeq[string](strings.NewReader(input), petColumns, FixedWidthOptions{}).Next()
+	assert.EqualError(t, err, "need a struct type, not string")
+}
@@ -0,0 +1,263 @@
+package seq
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Default time FollowLineSeq waits between checks of the file, like `tail -F`
+const defaultPollInterval = time.Second
+
+// Options for NewFollowLineSeq()
+type FollowOptions struct {
+	// How long to wait between checks for new data, truncation and rotation. 0 means 1s.
+	PollInterval time.Duration
+	// Also watch the file's directory with inotify, so that changes are picked up as soon as they happen
+	// rather than at the next poll. Only available on Linux; elsewhere, or if the watch can't be set up,
+	// FollowLineSeq just polls.
+	Inotify bool
+}
+
+// Something that wakes FollowLineSeq up to check the file again
+type followWatcher interface {
+	// Wait for a change or for d to pass, whichever comes first. Returns ctx.Err() if ctx is done.
+	wait(ctx context.Context, d time.Duration) error
+	close() error
+}
+
+// followWatcher that only waits for the poll interval
+type pollWatcher struct{}
+
+func (pollWatcher) wait(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (pollWatcher) close() error {
+	return nil
+}
+
+// Seq of the lines of a file that's still being written to, with the semantics of `tail -F`: it returns
+// the lines already in the file, then waits for more to be appended. Next() blocks until a whole line is
+// available, so a line being written is never returned half done. Lines end with '\n'; a '\r' before
+// the '\n' is left on the line, as with LineSeq.
+//
+// Add-ons: HasErr, HasIter, HasPosition
+//
+// The file is followed by name, so log rotation works: when path is renamed or deleted and a new file
+// created in its place, FollowLineSeq reads what's left of the old file (returning a final line without
+// a terminator, if there is one) and carries on from the start of the new one. If the file is truncated,
+// it carries on from the start, dropping any partial line. Either way, positions start again at 0 and
+// line 1. If the file doesn't exist yet, or not for a while during rotation, Next() waits for it to
+// appear.
+//
+// Next() returns ctx.Err() once ctx is done, including while it's waiting, and every call after that
+// returns the same error. Errors opening or reading the file are returned, and the next call tries
+// again. Call Close() to release the file if the sequence is abandoned before ctx is done.
+type FollowLineSeq struct {
+	*HasErr
+	*HasIter[string]
+	*HasPosition
+	ctx       context.Context
+	path      string
+	opts      FollowOptions
+	watcher   followWatcher
+	file      *os.File
+	offset    int64
+	pending   []byte
+	scanned   int
+	chunk     []byte
+	isRotated bool
+	errEnd    error
+}
+
+// C'tor function. Nothing is opened until the first call to Next().
+func NewFollowLineSeq(ctx context.Context, path string, opts FollowOptions) *FollowLineSeq {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaultPollInterval
+	}
+	sq := &FollowLineSeq{
+		HasErr:      NewHasErr(),
+		HasPosition: NewHasPosition(),
+		ctx:         ctx,
+		path:        path,
+		opts:        opts,
+		chunk:       make([]byte, defaultLineBufSize),
+	}
+	sq.HasPosition.SetFilename(path)
+	// HasIter needs the Seq object so it needs special treatment
+	sq.HasIter = NewHasIter(sq)
+	return sq
+}
+
+// Close the file and stop watching it. After that, Next() returns os.ErrClosed, or ctx.Err() if ctx is
+// already done.
+func (seq *FollowLineSeq) Close() error {
+	err := seq.closeFile()
+	if seq.watcher != nil {
+		seq.watcher.close()
+	}
+	if seq.errEnd == nil {
+		seq.errEnd = cmp.Or(seq.ctx.Err(), os.ErrClosed)
+	}
+	return err
+}
+
+func (seq *FollowLineSeq) closeFile() error {
+	if seq.file == nil {
+		return nil
+	}
+	err := seq.file.Close()
+	seq.file = nil
+	return err
+}
+
+// Open the file (again) and start from the beginning
+func (seq *FollowLineSeq) open() error {
+	file, err := os.Open(seq.path)
+	if err != nil {
+		return err
+	}
+	seq.file = file
+	seq.isRotated = false
+	seq.restart()
+	return nil
+}
+
+// Start again from the beginning of the file
+func (seq *FollowLineSeq) restart() {
+	seq.offset = 0
+	seq.pending = seq.pending[:0]
+	seq.scanned = 0
+	*seq.HasPosition = *NewHasPosition()
+	seq.HasPosition.SetFilename(seq.path)
+}
+
+// Wait for the file to change, falling back to polling if the watcher fails
+func (seq *FollowLineSeq) wait() error {
+	if seq.watcher == nil {
+		seq.watcher = pollWatcher{}
+		if seq.opts.Inotify {
+			if watcher, err := newInotifyWatcher(filepath.Dir(seq.path)); err == nil {
+				seq.watcher = watcher
+			}
+		}
+	}
+	err := seq.watcher.wait(seq.ctx, seq.opts.PollInterval)
+	if err != nil && seq.ctx.Err() == nil {
+		seq.watcher.close()
+		seq.watcher = pollWatcher{}
+		return nil
+	}
+	return err
+}
+
+// Whether the file at path is still the one being read, and whether it's been truncated
+func (seq *FollowLineSeq) check() (isRotated bool, isTruncated bool) {
+	pathInfo, err := os.Stat(seq.path)
+	if err != nil {
+		return true, false
+	}
+	fileInfo, err := seq.file.Stat()
+	if err != nil || !os.SameFile(fileInfo, pathInfo) {
+		return true, false
+	}
+	return false, fileInfo.Size() < seq.offset
+}
+
+// Remove the first n bytes of pending and return them as a line, without the '\n'
+func (seq *FollowLineSeq) take(n int) string {
+	line := seq.pending[:n]
+	seq.HasPosition.moveBytes(line)
+	text := string(bytes.TrimSuffix(line, []byte{'\n'}))
+	seq.pending = append(seq.pending[:0], seq.pending[n:]...)
+	seq.scanned = 0
+	seq.lastErr = nil
+	return text
+}
+
+// End the sequence with err
+func (seq *FollowLineSeq) fail(err error) (string, error) {
+	seq.Close()
+	seq.errEnd = err
+	seq.lastErr = err
+	return "", err
+}
+
+// Return the next line, waiting for it if need be
+func (seq *FollowLineSeq) Next() (string, error) {
+	seq.HasPosition.Update(0)
+	for {
+		if seq.errEnd != nil {
+			seq.lastErr = seq.errEnd
+			return "", seq.errEnd
+		}
+		if err := seq.ctx.Err(); err != nil {
+			return seq.fail(err)
+		}
+		if i := bytes.IndexByte(seq.pending[seq.scanned:], '\n'); i >= 0 {
+			return seq.take(seq.scanned + i + 1), nil
+		}
+		seq.scanned = len(seq.pending)
+		if seq.file == nil {
+			err := seq.open()
+			if errors.Is(err, fs.ErrNotExist) {
+				if err = seq.wait(); err != nil {
+					return seq.fail(err)
+				}
+				continue
+			}
+			if err != nil {
+				seq.lastErr = err
+				return "", err
+			}
+		}
+		n, err := seq.file.Read(seq.chunk)
+		seq.pending = append(seq.pending, seq.chunk[:n]...)
+		seq.offset += int64(n)
+		if n > 0 {
+			continue
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			seq.lastErr = err
+			return "", err
+		}
+		// At the end of the file for now
+		if seq.isRotated {
+			// Finished with the old file, after reading anything written to it since it was replaced
+			seq.closeFile()
+			if len(seq.pending) > 0 {
+				return seq.take(len(seq.pending)), nil
+			}
+			continue
+		}
+		isRotated, isTruncated := seq.check()
+		if isRotated {
+			seq.isRotated = true
+			continue
+		}
+		if isTruncated {
+			if _, err := seq.file.Seek(0, io.SeekStart); err != nil {
+				seq.closeFile()
+			}
+			seq.restart()
+			continue
+		}
+		if err := seq.wait(); err != nil {
+			return seq.fail(err)
+		}
+	}
+}
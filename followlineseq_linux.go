@@ -0,0 +1,53 @@
+//go:build linux
+
+package seq
+
+import (
+	"context"
+	"errors"
+	"os"
+	"syscall"
+	"time"
+)
+
+// followWatcher that wakes up on inotify events in the followed file's directory. Watching the directory
+// rather than the file catches the file being replaced as well as written to. Events for other files
+// just mean an early check, so they aren't told apart.
+type inotifyWatcher struct {
+	file *os.File
+	buf  []byte
+}
+
+func newInotifyWatcher(dir string) (followWatcher, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_NONBLOCK | syscall.IN_CLOEXEC)
+	if err != nil {
+		return nil, os.NewSyscallError("inotify_init1", err)
+	}
+	mask := uint32(syscall.IN_MODIFY | syscall.IN_ATTRIB | syscall.IN_CLOSE_WRITE | syscall.IN_CREATE |
+		syscall.IN_DELETE | syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO)
+	if _, err := syscall.InotifyAddWatch(fd, dir, mask); err != nil {
+		syscall.Close(fd)
+		return nil, os.NewSyscallError("inotify_add_watch", err)
+	}
+	// The fd is non-blocking, so os.File reads it through the runtime poller, which makes read deadlines work
+	return &inotifyWatcher{os.NewFile(uintptr(fd), "inotify"), make([]byte, 4096)}, nil
+}
+
+func (w *inotifyWatcher) wait(ctx context.Context, d time.Duration) error {
+	w.file.SetReadDeadline(time.Now().Add(d))
+	stop := context.AfterFunc(ctx, func() { w.file.SetReadDeadline(time.Now()) })
+	defer stop()
+	// Read (and discard) whatever events have queued up, or wait for one
+	_, err := w.file.Read(w.buf)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err != nil && !errors.Is(err, os.ErrDeadlineExceeded) {
+		return err
+	}
+	return nil
+}
+
+func (w *inotifyWatcher) close() error {
+	return w.file.Close()
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package seq
+
+import "errors"
+
+// inotify is Linux only; FollowLineSeq polls instead
+func newInotifyWatcher(dir string) (followWatcher, error) {
+	return nil, errors.New("inotify not supported")
+}
@@ -0,0 +1,107 @@
+package seq
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var followModes = []FollowOptions{{PollInterval: 5 * time.Millisecond}, {PollInterval: 5 * time.Millisecond, Inotify: true}}
+
+func appendFile(t *testing.T, path string, data string) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	assert.NoError(t, err)
+	_, err = f.WriteString(data)
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+}
+
+// Do f after a short wait, while the test is blocked in Next()
+func later(f func()) {
+	time.AfterFunc(20*time.Millisecond, f)
+}
+
+func testFollowNext(t *testing.T, sq *FollowLineSeq, expected string, expectedPos int) {
+	line, err := sq.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, expected, line)
+	assert.Equal(t, expectedPos, sq.LastPosition())
+}
+
+func TestFollowLineSeq(t *testing.T) {
+	for _, opts := range followModes {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		path := writeTestFile(t, "pets.log", []byte("Rex\nFi"))
+		sq := NewFollowLineSeq(ctx, path, opts)
+		testFollowNext(t, sq, "Rex", 0)
+		later(func() { appendFile(t, path, "do\nSpot\n") })
+		testFollowNext(t, sq, "Fido", 4)
+		assert.Equal(t, Position{path, 4, 2, 1, 1}, sq.LastLocation())
+		testFollowNext(t, sq, "Spot", 9)
+
+		// Truncated, then written from the start
+		later(func() {
+			assert.NoError(t, os.Truncate(path, 0))
+			appendFile(t, path, "Tom\n")
+		})
+		testFollowNext(t, sq, "Tom", 0)
+		assert.Equal(t, 1, sq.LastLocation().Line)
+
+		// Rotated: the old file is renamed, has a last line added, and a new one takes its place
+		assert.NoError(t, os.Rename(path, path+".1"))
+		appendFile(t, path+".1", "Felix")
+		testFollowNext(t, sq, "Felix", 4)
+		later(func() { appendFile(t, path, "Nemo\n") })
+		testFollowNext(t, sq, "Nemo", 0)
+		assert.Equal(t, path, sq.LastLocation().Filename)
+
+		later(cancel)
+		line, err := sq.Next()
+		assert.Equal(t, "", line)
+		assert.Equal(t, context.Canceled, err)
+		appendFile(t, path, "Dory\n")
+		_, err = sq.Next()
+		assert.Equal(t, context.Canceled, err)
+		assert.Equal(t, context.Canceled, sq.Err())
+	}
+}
+
+func TestFollowLineSeqWaitForFile(t *testing.T) {
+	for _, opts := range followModes {
+		path := filepath.Join(t.TempDir(), "pets.log")
+		sq := NewFollowLineSeq(context.Background(), path, opts)
+		later(func() { appendFile(t, path, "Rex\n") })
+		testFollowNext(t, sq, "Rex", 0)
+		// Deleted, and recreated later
+		assert.NoError(t, os.Remove(path))
+		later(func() { appendFile(t, path, "Fido\n") })
+		testFollowNext(t, sq, "Fido", 0)
+		assert.NoError(t, sq.Close())
+		_, err := sq.Next()
+		assert.True(t, errors.Is(err, os.ErrClosed))
+	}
+}
+
+func TestFollowLineSeqInotify(t *testing.T) {
+	path := writeTestFile(t, "pets.log", []byte("Rex\n"))
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	sq := NewFollowLineSeq(ctx, path, FollowOptions{PollInterval: time.Hour, Inotify: true})
+	testFollowNext(t, sq, "Rex", 0)
+	if runtime.GOOS == "linux" {
+		// inotify wakes Next() up long before the next poll
+		later(func() { appendFile(t, path, "Fido\n") })
+		testFollowNext(t, sq, "Fido", 4)
+	}
+	start := time.Now()
+	_, err := sq.Next()
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Less(t, time.Since(start), time.Second)
+}
@@ -0,0 +1,140 @@
+package seq
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Returned by FramedSeq for a frame longer than its maximum size
+var ErrFrameTooLarge = errors.New("frame too large")
+
+// Default maximum frame size for FramedSeq
+const defaultMaxFrameSize = 64 << 20
+
+// How FramedSeq reads the length in front of each frame
+type FrameFormat int
+
+const (
+	// Unsigned varint, as written by binary.AppendUvarint() and protobuf's delimited format. This is
+	// the default.
+	FrameUvarint FrameFormat = iota
+	// 4-byte big-endian length
+	FrameUint32BE
+	// 4-byte little-endian length
+	FrameUint32LE
+)
+
+// Seq of length-prefixed binary records (frames), returned by pointer as with ChunkSeq. An empty frame is
+// a pointer to an empty slice.
+//
+// Add-ons: HasErr, HasIter, HasPosition
+//
+// After each call to Next(), LastPosition() is the offset of the frame's length prefix and Position() is
+// the offset just past the frame. Input that ends cleanly between frames ends with io.EOF; input that
+// ends partway through a frame gives io.ErrUnexpectedEOF. A length over the maximum frame size (64MB
+// unless set with SetMaxFrameSize()) gives an error wrapping ErrFrameTooLarge, without reading the frame.
+// Both end the sequence, since there's no way to find the next frame.
+type FramedSeq struct {
+	*HasErr
+	*HasIter[*[]byte]
+	*HasPosition
+	brd     *bufio.Reader
+	format  FrameFormat
+	maxSize int
+	errEnd  error
+}
+
+// C'tor function
+func NewFramedSeq(rd io.Reader, format FrameFormat) *FramedSeq {
+	sq := &FramedSeq{
+		HasErr:      NewHasErr(),
+		HasPosition: NewHasPosition(),
+		brd:         bufio.NewReader(rd),
+		format:      format,
+		maxSize:     defaultMaxFrameSize,
+	}
+	sq.HasPosition.setFilenameFrom(rd)
+	// HasIter needs the Seq object so it needs special treatment
+	sq.HasIter = NewHasIter(sq)
+	return sq
+}
+
+// Set the maximum frame size, and return the FramedSeq
+func (seq *FramedSeq) SetMaxFrameSize(size int) *FramedSeq {
+	seq.maxSize = size
+	return seq
+}
+
+// Read a length prefix, returning the length and the size of the prefix
+func (seq *FramedSeq) readLength() (uint64, int, error) {
+	if seq.format == FrameUvarint {
+		counter := &countingByteReader{brd: seq.brd}
+		n, err := binary.ReadUvarint(counter)
+		return n, counter.n, err
+	}
+	var prefix [4]byte
+	nRead, err := io.ReadFull(seq.brd, prefix[:])
+	if err != nil {
+		return 0, nRead, err
+	}
+	if seq.format == FrameUint32LE {
+		return uint64(binary.LittleEndian.Uint32(prefix[:])), nRead, nil
+	}
+	return uint64(binary.BigEndian.Uint32(prefix[:])), nRead, nil
+}
+
+// Return the next frame
+func (seq *FramedSeq) Next() (*[]byte, error) {
+	if seq.errEnd != nil {
+		seq.HasPosition.Update(0)
+		seq.lastErr = seq.errEnd
+		return nil, seq.errEnd
+	}
+	length, nPrefix, err := seq.readLength()
+	seq.HasPosition.Update(nPrefix)
+	if err != nil {
+		// EOF partway through the prefix is unexpected; binary.ReadUvarint() already reports it that way
+		if errors.Is(err, io.EOF) && nPrefix > 0 {
+			err = io.ErrUnexpectedEOF
+		}
+		return seq.fail(err)
+	}
+	if length > uint64(seq.maxSize) {
+		return seq.fail(fmt.Errorf("%w: %d bytes at byte offset %d", ErrFrameTooLarge, length, seq.LastPosition()))
+	}
+	frame := make([]byte, length)
+	n, err := io.ReadFull(seq.brd, frame)
+	seq.pos += n
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return seq.fail(err)
+	}
+	seq.lastErr = nil
+	return &frame, nil
+}
+
+// End the sequence with err
+func (seq *FramedSeq) fail(err error) (*[]byte, error) {
+	seq.errEnd = err
+	seq.lastErr = err
+	return nil, err
+}
+
+// io.ByteReader that counts the bytes read through it
+type countingByteReader struct {
+	brd *bufio.Reader
+	n   int
+}
+
+func (cbr *countingByteReader) ReadByte() (byte, error) {
+	b, err := cbr.brd.ReadByte()
+	if err == nil {
+		cbr.n++
+	}
+	return b, err
+}
@@ -0,0 +1,85 @@
+package seq
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func frameUvarint(frames ...string) []byte {
+	b := []byte{}
+	for _, frame := range frames {
+		b = binary.AppendUvarint(b, uint64(len(frame)))
+		b = append(b, frame...)
+	}
+	return b
+}
+
+func TestFramedSeqUvarint(t *testing.T) {
+	long := string(bytes.Repeat([]byte{0xfe}, 300))
+	sq := NewFramedSeq(bytes.NewReader(frameUvarint("Rex", "", long, "Fido")), FrameUvarint)
+	frames := []string{}
+	for frame := range Iter(sq) {
+		frames = append(frames, string(*frame))
+	}
+	assert.Equal(t, []string{"Rex", "", long, "Fido"}, frames)
+	assert.Equal(t, io.EOF, sq.Err())
+	assert.Equal(t, 4+1+302+5, sq.Position())
+}
+
+func TestFramedSeqUint32(t *testing.T) {
+	input := []byte{0, 0, 0, 3, 'R', 'e', 'x', 0, 0, 0, 1, 0}
+	sq := NewFramedSeq(bytes.NewReader(input), FrameUint32BE)
+	frame, err := sq.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("Rex"), *frame)
+	frame, err = sq.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0}, *frame)
+	assert.Equal(t, 7, sq.LastPosition())
+	assert.Equal(t, 12, sq.Position())
+	frame, err = sq.Next()
+	assert.Nil(t, frame)
+	assert.Equal(t, io.EOF, err)
+
+	input = []byte{3, 0, 0, 0, 'R', 'e', 'x'}
+	sq = NewFramedSeq(bytes.NewReader(input), FrameUint32LE)
+	frame, err = sq.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("Rex"), *frame)
+}
+
+func TestFramedSeqTruncated(t *testing.T) {
+	input := frameUvarint("Rex", "Fido")
+	for _, n := range []int{len(input) - 1, 5, 6} {
+		sq := NewFramedSeq(bytes.NewReader(input[:n]), FrameUvarint)
+		frame, err := sq.Next()
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("Rex"), *frame)
+		frame, err = sq.Next()
+		assert.Nil(t, frame)
+		assert.Equal(t, io.ErrUnexpectedEOF, err, n)
+		_, err = sq.Next()
+		assert.Equal(t, io.ErrUnexpectedEOF, err)
+	}
+	sq := NewFramedSeq(bytes.NewReader([]byte{0, 0}), FrameUint32LE)
+	_, err := sq.Next()
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+	// A varint prefix cut off partway through
+	sq = NewFramedSeq(bytes.NewReader([]byte{0x80}), FrameUvarint)
+	_, err = sq.Next()
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+}
+
+func TestFramedSeqTooLarge(t *testing.T) {
+	sq := NewFramedSeq(bytes.NewReader(frameUvarint("Rex", "Fido")), FrameUvarint).SetMaxFrameSize(3)
+	sq.Next()
+	frame, err := sq.Next()
+	assert.Nil(t, frame)
+	assert.True(t, errors.Is(err, ErrFrameTooLarge))
+	assert.EqualError(t, err, "frame too large: 4 bytes at byte offset 4")
+}
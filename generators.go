@@ -0,0 +1,218 @@
+package seq
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrZeroStep is returned by a RangeSeq created with a step of 0, which would never reach its end
+var ErrZeroStep = errors.New("range step must not be zero")
+
+// Types that RangeSeq can count with
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64
+}
+
+// Seq of numbers from start up to (but not including) end, counting by step. A negative step counts
+// down from start to end.
+//
+// Add-ons: HasErr, HasIter
+//
+// Note that the first element of Range(0, n, 1) is 0, the zero value, returned with a nil error. That's a
+// legitimate element; only (0, io.EOF) marks the end of the sequence.
+type RangeSeq[T Number] struct {
+	*HasErr
+	*HasIter[T]
+	next T
+	end  T
+	step T
+}
+
+// C'tor function
+func Range[T Number](start, end, step T) *RangeSeq[T] {
+	sq := &RangeSeq[T]{
+		HasErr: NewHasErr(),
+		next:   start,
+		end:    end,
+		step:   step,
+	}
+	// HasIter needs the Seq object so it needs special treatment
+	sq.HasIter = NewHasIter(sq)
+	return sq
+}
+
+func (seq *RangeSeq[T]) Next() (T, error) {
+	if seq.step == 0 {
+		seq.lastErr = ErrZeroStep
+		return *new(T), ErrZeroStep
+	}
+	var zero T
+	isDown := seq.step < zero
+	if (!isDown && seq.next >= seq.end) || (isDown && seq.next <= seq.end) {
+		seq.lastErr = io.EOF
+		return *new(T), io.EOF
+	}
+	val := seq.next
+	seq.next += seq.step
+	// Stop rather than wrap around if the next value overflows
+	if (!isDown && seq.next < val) || (isDown && seq.next > val) {
+		seq.next = seq.end
+	}
+	seq.lastErr = nil
+	return val, nil
+}
+
+// Seq that returns the same value n times, or forever if n is negative
+//
+// Add-ons: HasErr, HasIter
+type RepeatSeq[T comparable] struct {
+	*HasErr
+	*HasIter[T]
+	val T
+	n   int
+	i   int
+}
+
+// C'tor function
+func Repeat[T comparable](val T, n int) *RepeatSeq[T] {
+	sq := &RepeatSeq[T]{
+		HasErr: NewHasErr(),
+		val:    val,
+		n:      n,
+	}
+	// HasIter needs the Seq object so it needs special treatment
+	sq.HasIter = NewHasIter(sq)
+	return sq
+}
+
+func (seq *RepeatSeq[T]) Next() (T, error) {
+	if seq.n >= 0 && seq.i >= seq.n {
+		seq.lastErr = io.EOF
+		return *new(T), io.EOF
+	}
+	seq.i++
+	seq.lastErr = nil
+	return seq.val, nil
+}
+
+// Seq that returns the elements of another Seq over and over again. Elements are buffered during the
+// first pass, and replayed from the buffer afterwards, so the inner Seq is only read once.
+//
+// Add-ons: HasErr, HasIter
+//
+// An empty inner Seq gives an empty CycleSeq. An error from the inner Seq ends the CycleSeq with that error.
+type CycleSeq[T comparable] struct {
+	*HasErr
+	*HasIter[T]
+	inner  *cursor[T]
+	buffer []T
+	i      int
+	isDone bool
+}
+
+// C'tor function
+func Cycle[T comparable](sqInner Seq[T]) *CycleSeq[T] {
+	sq := &CycleSeq[T]{
+		HasErr: NewHasErr(),
+		inner:  newCursor(sqInner),
+	}
+	// HasIter needs the Seq object so it needs special treatment
+	sq.HasIter = NewHasIter(sq)
+	return sq
+}
+
+func (seq *CycleSeq[T]) Next() (T, error) {
+	// First pass: read from the inner Seq and remember each element
+	if !seq.isDone {
+		seq.inner.advance()
+		if seq.inner.ok {
+			seq.buffer = append(seq.buffer, seq.inner.val)
+			seq.lastErr = nil
+			return seq.inner.val, nil
+		}
+		if seq.inner.err != nil {
+			seq.lastErr = seq.inner.err
+			return *new(T), seq.inner.err
+		}
+		seq.isDone = true
+	}
+	if len(seq.buffer) == 0 {
+		seq.lastErr = io.EOF
+		return *new(T), io.EOF
+	}
+	val := seq.buffer[seq.i]
+	seq.i = (seq.i + 1) % len(seq.buffer)
+	seq.lastErr = nil
+	return val, nil
+}
+
+// Function for Unfold(). Takes the current state and returns the next element and the next state.
+// Returning an error (typically io.EOF) ends the sequence.
+type UnfoldFunc[S any, T comparable] func(state S) (T, S, error)
+
+// Seq built by repeatedly applying a function to a state, starting from a seed. Useful for sequences
+// where each element depends on the previous one, eg Fibonacci numbers or pages of an API response.
+//
+// Add-ons: HasErr, HasIter
+type UnfoldSeq[S any, T comparable] struct {
+	*HasErr
+	*HasIter[T]
+	state S
+	fn    UnfoldFunc[S, T]
+}
+
+// C'tor function
+func Unfold[S any, T comparable](seed S, fn UnfoldFunc[S, T]) *UnfoldSeq[S, T] {
+	sq := &UnfoldSeq[S, T]{
+		HasErr: NewHasErr(),
+		state:  seed,
+		fn:     fn,
+	}
+	// HasIter needs the Seq object so it needs special treatment
+	sq.HasIter = NewHasIter(sq)
+	return sq
+}
+
+// Once fn returns an error, fn is not called again and all further calls return (*new(T), err)
+func (seq *UnfoldSeq[S, T]) Next() (T, error) {
+	if seq.lastErr != nil {
+		return *new(T), seq.lastErr
+	}
+	val, state, err := seq.fn(seq.state)
+	seq.state = state
+	seq.lastErr = err
+	return val, err
+}
+
+// Seq that gets each element by calling a function. fn returns io.EOF to end the sequence, or any other
+// error to fail it. Like Limit(), fn may return a final element together with io.EOF.
+//
+// Add-ons: HasErr, HasIter
+type GenerateSeq[T comparable] struct {
+	*HasErr
+	*HasIter[T]
+	fn NextFunc1[T]
+}
+
+// C'tor function
+func Generate[T comparable](fn NextFunc1[T]) *GenerateSeq[T] {
+	sq := &GenerateSeq[T]{
+		HasErr: NewHasErr(),
+		fn:     fn,
+	}
+	// HasIter needs the Seq object so it needs special treatment
+	sq.HasIter = NewHasIter(sq)
+	return sq
+}
+
+// Once fn returns an error, fn is not called again and all further calls return (*new(T), err)
+func (seq *GenerateSeq[T]) Next() (T, error) {
+	if seq.lastErr != nil {
+		return *new(T), seq.lastErr
+	}
+	val, err := seq.fn()
+	seq.lastErr = err
+	return val, err
+}
@@ -0,0 +1,88 @@
+package seq
+
+import (
+	"errors"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRange(t *testing.T) {
+	sq := Range(0, 5, 2)
+	assert.Equal(t, []int{0, 2, 4}, collectSeq[int](t, sq))
+	testEof(t, sq)
+}
+
+func TestRangeDown(t *testing.T) {
+	vals := []float64{}
+	for val := range Range(1.0, 0.0, -0.25).Iter() {
+		vals = append(vals, val)
+	}
+	assert.Equal(t, []float64{1.0, 0.75, 0.5, 0.25}, vals)
+}
+
+func TestRangeOverflow(t *testing.T) {
+	sq := Range[uint8](250, math.MaxUint8, 4)
+	assert.Equal(t, []uint8{250, 254}, collectSeq[uint8](t, sq))
+}
+
+func TestRangeZeroStep(t *testing.T) {
+	_, err := Range(0, 5, 0).Next()
+	assert.True(t, errors.Is(err, ErrZeroStep))
+}
+
+func TestRepeat(t *testing.T) {
+	sq := Repeat("Rex", 3)
+	n, err := Count(sq)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, n)
+	// Negative n repeats forever
+	assert.Len(t, collectSeq(t, Limit(Repeat("Rex", -1), 100)), 100)
+}
+
+func TestCycle(t *testing.T) {
+	sq := Limit(Cycle(newTestLineSeq("a", "b", "c")), 7)
+	assert.Equal(t, []string{"a", "b", "c", "a", "b", "c", "a"}, collectSeq(t, sq))
+}
+
+func TestCycleEmpty(t *testing.T) {
+	// newTestLineSeq() with no lines is a single empty line, so filter it out
+	sq := Where(newTestLineSeq(), func(s string) bool { return s != "" })
+	testEof(t, Cycle[string](sq))
+}
+
+func TestUnfoldFibonacci(t *testing.T) {
+	fib := func(state [2]int) (int, [2]int, error) {
+		if state[0] > 50 {
+			return 0, state, io.EOF
+		}
+		return state[0], [2]int{state[1], state[0] + state[1]}, nil
+	}
+	sq := Unfold([2]int{0, 1}, fib)
+	assert.Equal(t, []int{0, 1, 1, 2, 3, 5, 8, 13, 21, 34}, collectSeq[int](t, sq))
+	testEof(t, sq)
+}
+
+func TestGenerate(t *testing.T) {
+	errBoom := errors.New("boom")
+	i := 0
+	sq := Generate(func() (string, error) {
+		i++
+		if i > 2 {
+			return "", errBoom
+		}
+		return "Rex", nil
+	})
+	names := []string{}
+	for name := range sq.Iter() {
+		names = append(names, name)
+	}
+	assert.Equal(t, []string{"Rex", "Rex"}, names)
+	assert.True(t, errors.Is(sq.Err(), errBoom))
+	// fn isn't called again after an error
+	_, err := sq.Next()
+	assert.True(t, errors.Is(err, errBoom))
+	assert.Equal(t, 3, i)
+}
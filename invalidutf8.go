@@ -0,0 +1,90 @@
+package seq
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+// Matches any *InvalidUTF8Error with errors.Is()
+var ErrInvalidUTF8 = errors.New("invalid UTF-8")
+
+// What RuneSeq and LineSeq do when they come across bytes that aren't valid UTF-8
+type InvalidUTF8Policy int
+
+const (
+	// Pass invalid bytes through: RuneSeq returns utf8.RuneError for each bad byte, and LineSeq returns
+	// lines as-is. This is the default.
+	InvalidUTF8PassThrough InvalidUTF8Policy = iota
+	// Replace each invalid sequence with a single utf8.RuneError (U+FFFD), and count it
+	InvalidUTF8Replace
+	// Return an *InvalidUTF8Error
+	InvalidUTF8Fail
+)
+
+// Error for invalid UTF-8, with the byte offset where it starts and the offending bytes
+type InvalidUTF8Error struct {
+	Offset int
+	Bytes  []byte
+}
+
+func (e *InvalidUTF8Error) Error() string {
+	return fmt.Sprintf("invalid UTF-8 at byte offset %d: % x", e.Offset, e.Bytes)
+}
+
+func (e *InvalidUTF8Error) Is(target error) bool {
+	return target == ErrInvalidUTF8
+}
+
+// Length of the invalid sequence at the start of p: a lead byte plus any continuation bytes that follow
+// it, up to the length the lead byte calls for. Stray continuation bytes and bytes that can never appear
+// in UTF-8 are invalid on their own.
+func invalidSeqLen(p []byte) int {
+	if len(p) == 0 {
+		return 0
+	}
+	var want int
+	switch b := p[0]; {
+	case b >= 0xc2 && b <= 0xdf:
+		want = 2
+	case b >= 0xe0 && b <= 0xef:
+		want = 3
+	case b >= 0xf0 && b <= 0xf4:
+		want = 4
+	default:
+		return 1
+	}
+	n := 1
+	for n < want && n < len(p) && p[n]&0xc0 == 0x80 {
+		n++
+	}
+	return n
+}
+
+// Return the index and length of the first invalid sequence in p, or (-1, 0) if p is valid UTF-8
+func findInvalidUTF8(p []byte) (int, int) {
+	for i := 0; i < len(p); {
+		ru, size := utf8.DecodeRune(p[i:])
+		if ru == utf8.RuneError && size == 1 {
+			return i, invalidSeqLen(p[i:])
+		}
+		i += size
+	}
+	return -1, 0
+}
+
+// Return a copy of p with each invalid sequence replaced by U+FFFD, and the number of replacements
+func replaceInvalidUTF8(p []byte) ([]byte, int) {
+	out := make([]byte, 0, len(p)+8)
+	n := 0
+	for {
+		i, size := findInvalidUTF8(p)
+		if i < 0 {
+			return append(out, p...), n
+		}
+		out = append(out, p[:i]...)
+		out = utf8.AppendRune(out, utf8.RuneError)
+		p = p[i+size:]
+		n++
+	}
+}
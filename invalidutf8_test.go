@@ -0,0 +1,72 @@
+package seq
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// "Zoë" with the 'ë' cut short, then a stray continuation byte
+const badUTF8 = "Zo\xc3 \x80!"
+
+func TestRuneSeqInvalidPassThrough(t *testing.T) {
+	sq := NewRuneSeq(strings.NewReader(badUTF8))
+	runes := collectSeq[rune](t, sq)
+	assert.Equal(t, []rune{'Z', 'o', utf8.RuneError, ' ', utf8.RuneError, '!'}, runes)
+	assert.Equal(t, 2, sq.InvalidCount())
+	assert.Equal(t, len(badUTF8), sq.Position())
+}
+
+func TestRuneSeqInvalidReplace(t *testing.T) {
+	// A truncated 3-byte sequence is replaced by a single U+FFFD
+	str := "a\xe2\x82b"
+	sq := NewRuneSeq(strings.NewReader(str)).SetInvalidUTF8Policy(InvalidUTF8Replace)
+	runes := collectSeq[rune](t, sq)
+	assert.Equal(t, []rune{'a', utf8.RuneError, 'b'}, runes)
+	assert.Equal(t, 1, sq.InvalidCount())
+	assert.Equal(t, len(str), sq.Position())
+}
+
+func TestRuneSeqInvalidFail(t *testing.T) {
+	sq := NewRuneSeq(strings.NewReader(badUTF8)).SetInvalidUTF8Policy(InvalidUTF8Fail)
+	sq.Next()
+	sq.Next()
+	ru, err := sq.Next()
+	assert.Equal(t, rune(0), ru)
+	assert.True(t, errors.Is(err, ErrInvalidUTF8))
+	var invalidErr *InvalidUTF8Error
+	assert.True(t, errors.As(sq.Err(), &invalidErr))
+	assert.Equal(t, 2, invalidErr.Offset)
+	assert.Equal(t, []byte{0xc3}, invalidErr.Bytes)
+	assert.Equal(t, "invalid UTF-8 at byte offset 2: c3", err.Error())
+	// The bad bytes have been consumed, so reading can carry on
+	ru, err = sq.Next()
+	testNextOk(t, ' ', ru, err)
+	// The rejected sequence isn't counted as returned
+	assert.Equal(t, 3, sq.Index())
+}
+
+func TestLineSeqInvalidUTF8(t *testing.T) {
+	str := "Rex\n" + badUTF8 + "\nFido\n"
+	// Pass through
+	sq := FromString(str)
+	assert.Equal(t, []string{"Rex", badUTF8, "Fido"}, collectSeq[string](t, sq))
+	// Replace
+	sq = NewLineSeqWithOptions(strings.NewReader(str), LineSeqOptions{InvalidUTF8: InvalidUTF8Replace})
+	assert.Equal(t, []string{"Rex", "Zo� �!", "Fido"}, collectSeq[string](t, sq))
+	assert.Equal(t, 2, sq.InvalidCount())
+	// Fail
+	sq = NewLineSeqWithOptions(strings.NewReader(str), LineSeqOptions{InvalidUTF8: InvalidUTF8Fail})
+	line, err := sq.Next()
+	testNextOk(t, "Rex", line, err)
+	_, err = sq.Next()
+	var invalidErr *InvalidUTF8Error
+	assert.True(t, errors.As(err, &invalidErr))
+	assert.Equal(t, 6, invalidErr.Offset)
+	assert.Equal(t, 2, sq.LastLocation().Line)
+	line, err = sq.Next()
+	testNextOk(t, "Fido", line, err)
+}
@@ -0,0 +1,212 @@
+package seq
+
+import (
+	"cmp"
+	"io"
+)
+
+// Element of a LeftJoin(). Matched is false when no element of the right sequence had the same key as
+// Left, in which case Right is the zero value.
+type LeftJoinRow[L comparable, R comparable] struct {
+	Left    L
+	Right   R
+	Matched bool
+}
+
+// joiner produces the rows of a join one at a time. Unmatched left elements are only returned by outer
+// joins, with matched set to false.
+type joiner[L comparable, R comparable] interface {
+	next() (left L, right R, matched bool, err error)
+}
+
+// Hash join: the right sequence is read into a map on the first call, then the left sequence is streamed
+type hashJoiner[L comparable, R comparable, K comparable] struct {
+	left      *cursor[L]
+	right     *cursor[R]
+	leftKey   KeyFunc[L, K]
+	rightKey  KeyFunc[R, K]
+	isOuter   bool
+	index     map[K][]R
+	matches   []R
+	i         int
+	isStarted bool
+}
+
+func (j *hashJoiner[L, R, K]) next() (L, R, bool, error) {
+	if !j.isStarted {
+		j.isStarted = true
+		for j.right.advance(); j.right.ok; j.right.advance() {
+			key := j.rightKey(j.right.val)
+			j.index[key] = append(j.index[key], j.right.val)
+		}
+		if j.right.err != nil {
+			return *new(L), *new(R), false, &SideError{SideRight, j.right.err}
+		}
+		j.left.advance()
+	}
+	for {
+		if j.i < len(j.matches) {
+			r := j.matches[j.i]
+			j.i++
+			return j.left.val, r, true, nil
+		}
+		// Done with the current left element (if any), so move on to the next one
+		if j.matches != nil {
+			j.matches = nil
+			j.left.advance()
+		}
+		if j.left.err != nil {
+			return *new(L), *new(R), false, &SideError{SideLeft, j.left.err}
+		}
+		if !j.left.ok {
+			return *new(L), *new(R), false, io.EOF
+		}
+		matches, hasKey := j.index[j.leftKey(j.left.val)]
+		if hasKey {
+			j.matches, j.i = matches, 0
+			continue
+		}
+		l := j.left.val
+		j.left.advance()
+		if j.isOuter {
+			return l, *new(R), false, nil
+		}
+	}
+}
+
+// Sorted-merge join: both sequences must be sorted by key in ascending order. Only the run of right
+// elements sharing the current key is buffered.
+type sortedJoiner[L comparable, R comparable, K cmp.Ordered] struct {
+	left      *cursor[L]
+	right     *cursor[R]
+	leftKey   KeyFunc[L, K]
+	rightKey  KeyFunc[R, K]
+	isOuter   bool
+	group     []R
+	groupKey  K
+	i         int
+	isActive  bool
+	isStarted bool
+}
+
+func (j *sortedJoiner[L, R, K]) next() (L, R, bool, error) {
+	if !j.isStarted {
+		j.isStarted = true
+		j.left.advance()
+		j.right.advance()
+	}
+	for {
+		if j.isActive {
+			if j.i < len(j.group) {
+				r := j.group[j.i]
+				j.i++
+				return j.left.val, r, true, nil
+			}
+			j.isActive = false
+			j.left.advance()
+		}
+		if j.left.err != nil {
+			return *new(L), *new(R), false, &SideError{SideLeft, j.left.err}
+		}
+		if !j.left.ok {
+			return *new(L), *new(R), false, io.EOF
+		}
+		key := j.leftKey(j.left.val)
+		// Several left elements can share a key, so reuse the group from the previous left element
+		if len(j.group) > 0 && j.groupKey == key {
+			j.isActive, j.i = true, 0
+			continue
+		}
+		for j.right.ok && j.rightKey(j.right.val) < key {
+			j.right.advance()
+		}
+		j.group = j.group[:0]
+		for j.right.ok && j.rightKey(j.right.val) == key {
+			j.group = append(j.group, j.right.val)
+			j.right.advance()
+		}
+		if j.right.err != nil {
+			return *new(L), *new(R), false, &SideError{SideRight, j.right.err}
+		}
+		if len(j.group) > 0 {
+			j.groupKey, j.isActive, j.i = key, true, 0
+			continue
+		}
+		l := j.left.val
+		j.left.advance()
+		if j.isOuter {
+			return l, *new(R), false, nil
+		}
+	}
+}
+
+type seqJoin[L comparable, R comparable] struct {
+	*HasErr
+	joiner joiner[L, R]
+}
+
+func (sq *seqJoin[L, R]) Next() (Pair[L, R], error) {
+	l, r, _, err := sq.joiner.next()
+	sq.lastErr = err
+	if err != nil {
+		return *new(Pair[L, R]), err
+	}
+	return Pair[L, R]{l, r}, nil
+}
+
+type seqLeftJoin[L comparable, R comparable] struct {
+	*HasErr
+	joiner joiner[L, R]
+}
+
+func (sq *seqLeftJoin[L, R]) Next() (LeftJoinRow[L, R], error) {
+	l, r, matched, err := sq.joiner.next()
+	sq.lastErr = err
+	if err != nil {
+		return *new(LeftJoinRow[L, R]), err
+	}
+	return LeftJoinRow[L, R]{l, r, matched}, nil
+}
+
+func newHashJoiner[L comparable, R comparable, K comparable](left Seq[L], right Seq[R], leftKey KeyFunc[L, K], rightKey KeyFunc[R, K], isOuter bool) *hashJoiner[L, R, K] {
+	return &hashJoiner[L, R, K]{
+		left:     newCursor(left),
+		right:    newCursor(right),
+		leftKey:  leftKey,
+		rightKey: rightKey,
+		isOuter:  isOuter,
+		index:    map[K][]R{},
+	}
+}
+
+func newSortedJoiner[L comparable, R comparable, K cmp.Ordered](left Seq[L], right Seq[R], leftKey KeyFunc[L, K], rightKey KeyFunc[R, K], isOuter bool) *sortedJoiner[L, R, K] {
+	return &sortedJoiner[L, R, K]{
+		left:     newCursor(left),
+		right:    newCursor(right),
+		leftKey:  leftKey,
+		rightKey: rightKey,
+		isOuter:  isOuter,
+	}
+}
+
+// Return a Pair for every combination of left and right elements with equal keys, in left order. right is
+// read into memory on the first call to Next().
+func Join[L comparable, R comparable, K comparable](left Seq[L], right Seq[R], leftKey KeyFunc[L, K], rightKey KeyFunc[R, K]) *seqJoin[L, R] {
+	return &seqJoin[L, R]{NewHasErr(), newHashJoiner(left, right, leftKey, rightKey, false)}
+}
+
+// Like Join(), but left elements without a match are also returned, with Matched set to false
+func LeftJoin[L comparable, R comparable, K comparable](left Seq[L], right Seq[R], leftKey KeyFunc[L, K], rightKey KeyFunc[R, K]) *seqLeftJoin[L, R] {
+	return &seqLeftJoin[L, R]{NewHasErr(), newHashJoiner(left, right, leftKey, rightKey, true)}
+}
+
+// Like Join(), but for inputs sorted by key in ascending order. Both inputs are streamed; only right
+// elements sharing a single key are held in memory.
+func JoinSorted[L comparable, R comparable, K cmp.Ordered](left Seq[L], right Seq[R], leftKey KeyFunc[L, K], rightKey KeyFunc[R, K]) *seqJoin[L, R] {
+	return &seqJoin[L, R]{NewHasErr(), newSortedJoiner(left, right, leftKey, rightKey, false)}
+}
+
+// Like LeftJoin(), but for inputs sorted by key in ascending order
+func LeftJoinSorted[L comparable, R comparable, K cmp.Ordered](left Seq[L], right Seq[R], leftKey KeyFunc[L, K], rightKey KeyFunc[R, K]) *seqLeftJoin[L, R] {
+	return &seqLeftJoin[L, R]{NewHasErr(), newSortedJoiner(left, right, leftKey, rightKey, true)}
+}
@@ -0,0 +1,75 @@
+package seq
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func idOf(line string) string {
+	id, _, _ := strings.Cut(line, ",")
+	return id
+}
+
+func TestJoin(t *testing.T) {
+	names := newTestLineSeq("1,Rex", "2,Fido", "3,Spot")
+	owners := newTestLineSeq("3,Carol", "1,Alice", "1,Bob")
+	sq := Join(names, owners, idOf, idOf)
+	expected := []Pair[string, string]{
+		{"1,Rex", "1,Alice"},
+		{"1,Rex", "1,Bob"},
+		{"3,Spot", "3,Carol"},
+	}
+	assert.Equal(t, expected, collectSeq(t, sq))
+	testEof(t, sq)
+}
+
+func TestLeftJoin(t *testing.T) {
+	names := newTestLineSeq("1,Rex", "2,Fido")
+	owners := newTestLineSeq("1,Alice")
+	sq := LeftJoin(names, owners, idOf, idOf)
+	expected := []LeftJoinRow[string, string]{
+		{"1,Rex", "1,Alice", true},
+		{"2,Fido", "", false},
+	}
+	assert.Equal(t, expected, collectSeq(t, sq))
+}
+
+func TestJoinSorted(t *testing.T) {
+	names := newTestLineSeq("1,Rex", "1,Rover", "2,Fido", "4,Max")
+	owners := newTestLineSeq("0,Zed", "1,Alice", "1,Bob", "4,Dan", "5,Eve")
+	sq := JoinSorted(names, owners, idOf, idOf)
+	expected := []Pair[string, string]{
+		{"1,Rex", "1,Alice"},
+		{"1,Rex", "1,Bob"},
+		{"1,Rover", "1,Alice"},
+		{"1,Rover", "1,Bob"},
+		{"4,Max", "4,Dan"},
+	}
+	assert.Equal(t, expected, collectSeq(t, sq))
+	testEof(t, sq)
+}
+
+func TestLeftJoinSorted(t *testing.T) {
+	names := newTestLineSeq("1,Rex", "2,Fido", "3,Spot")
+	owners := newTestLineSeq("2,Bob")
+	sq := LeftJoinSorted(names, owners, idOf, idOf)
+	expected := []LeftJoinRow[string, string]{
+		{"1,Rex", "", false},
+		{"2,Fido", "2,Bob", true},
+		{"3,Spot", "", false},
+	}
+	assert.Equal(t, expected, collectSeq(t, sq))
+}
+
+func TestJoinSideError(t *testing.T) {
+	errBoom := errors.New("boom")
+	sq := Join(newTestLineSeq("1,Rex"), &errAfterSeq{newTestLineSeq("1,Alice"), errBoom}, idOf, idOf)
+	_, err := sq.Next()
+	var sideErr *SideError
+	assert.True(t, errors.As(err, &sideErr))
+	assert.Equal(t, SideRight, sideErr.Side)
+	assert.True(t, errors.Is(sq.Err(), errBoom))
+}
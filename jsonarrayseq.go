@@ -0,0 +1,201 @@
+package seq
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Returned by JSONArraySeq when its path doesn't lead to an array
+var ErrJSONPath = errors.New("no array at JSON path")
+
+// Error for an array element that couldn't be decoded, with its index in the array and the byte offset
+// where the decoder was when it gave up
+type JSONArrayError struct {
+	Index  int
+	Offset int
+	Err    error
+}
+
+func (e *JSONArrayError) Error() string {
+	return fmt.Sprintf("element %d (byte offset %d): %v", e.Index, e.Offset, e.Err)
+}
+
+func (e *JSONArrayError) Unwrap() error {
+	return e.Err
+}
+
+// Seq of the elements of a JSON array, decoded one at a time with json.Decoder so that the whole
+// document is never held in memory. The array can be the whole document, or nested inside objects at a
+// path like ".data.items". Elements are returned by pointer, as with JSONLSeq.
+//
+// Add-ons: HasErr, HasIter, HasPosition
+//
+// Positions are byte offsets only: after each call to Next(), LastPosition() is where the decoder was
+// before the element (just after the previous element) and Position() is just after it.
+//
+// An element that doesn't fit T (eg a string where T has an int) is handled according to the
+// MalformedPolicy, as in JSONLSeq. Syntax errors always end the sequence, since the decoder can't
+// recover from them.
+type JSONArraySeq[T any] struct {
+	*HasErr
+	*HasIter[*T]
+	*HasPosition
+	dec       *json.Decoder
+	path      []string
+	policy    MalformedPolicy
+	isStarted bool
+	isDone    bool
+	index     int
+	nSkipped  int
+}
+
+// C'tor function, for a document that is a JSON array
+func NewJSONArraySeq[T any](rd io.Reader) *JSONArraySeq[T] {
+	return NewJSONArraySeqPath[T](rd, "")
+}
+
+// C'tor function, for an array nested inside objects. path is a list of keys, each preceded by a '.',
+// eg ".data.items" for {"data": {"items": [...]}}. "" or "." is the top level.
+func NewJSONArraySeqPath[T any](rd io.Reader, path string) *JSONArraySeq[T] {
+	sq := &JSONArraySeq[T]{
+		HasErr:      NewHasErr(),
+		HasPosition: NewHasPosition(),
+		dec:         json.NewDecoder(rd),
+	}
+	for _, key := range strings.Split(path, ".") {
+		if key != "" {
+			sq.path = append(sq.path, key)
+		}
+	}
+	// HasIter needs the Seq object so it needs special treatment
+	sq.HasIter = NewHasIter(sq)
+	return sq
+}
+
+// Set the policy for elements that can't be decoded into T, and return the JSONArraySeq
+func (seq *JSONArraySeq[T]) SetMalformedPolicy(policy MalformedPolicy) *JSONArraySeq[T] {
+	seq.policy = policy
+	return seq
+}
+
+// Number of elements skipped under MalformedSkip
+func (seq *JSONArraySeq[T]) Skipped() int {
+	return seq.nSkipped
+}
+
+// Read a token, which must be the delimiter want
+func (seq *JSONArraySeq[T]) expect(want json.Delim, where string) error {
+	tok, err := seq.dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != want {
+		if str, ok := tok.(string); ok {
+			tok = strconv.Quote(str)
+		}
+		return fmt.Errorf("%w %s: found %v at byte offset %d", ErrJSONPath, where, tok, seq.dec.InputOffset())
+	}
+	return nil
+}
+
+// Skip over the value that starts with the next token
+func (seq *JSONArraySeq[T]) skipValue() error {
+	depth := 0
+	for {
+		tok, err := seq.dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+// Walk down the path to the array, and read its opening '['
+func (seq *JSONArraySeq[T]) start() error {
+	where := ""
+	for _, key := range seq.path {
+		if err := seq.expect(json.Delim('{'), cmp.Or(where, ".")); err != nil {
+			return err
+		}
+		where += "." + key
+		for {
+			if !seq.dec.More() {
+				return fmt.Errorf("%w %s: key not found", ErrJSONPath, where)
+			}
+			tok, err := seq.dec.Token()
+			if err != nil {
+				return err
+			}
+			if tok == key {
+				break
+			}
+			if err := seq.skipValue(); err != nil {
+				return err
+			}
+		}
+	}
+	return seq.expect(json.Delim('['), cmp.Or(where, "."))
+}
+
+// Return the next element of the array
+func (seq *JSONArraySeq[T]) Next() (*T, error) {
+	if seq.isDone {
+		return nil, seq.lastErr
+	}
+	if !seq.isStarted {
+		seq.isStarted = true
+		if err := seq.start(); err != nil {
+			return seq.fail(err)
+		}
+	}
+	for {
+		start := int(seq.dec.InputOffset())
+		if !seq.dec.More() {
+			// Anything after the array is ignored
+			if err := seq.expect(json.Delim(']'), "end of array"); err != nil {
+				return seq.fail(err)
+			}
+			seq.lastPos, seq.pos = start, int(seq.dec.InputOffset())
+			return seq.fail(io.EOF)
+		}
+		val := new(T)
+		err := seq.dec.Decode(val)
+		seq.lastPos, seq.pos = start, int(seq.dec.InputOffset())
+		index := seq.index
+		seq.index++
+		if err == nil {
+			seq.lastErr = nil
+			return val, nil
+		}
+		var typeErr *json.UnmarshalTypeError
+		if !errors.As(err, &typeErr) || seq.policy == MalformedFail {
+			return seq.fail(&JSONArrayError{index, int(seq.dec.InputOffset()), err})
+		}
+		if seq.policy == MalformedSkip {
+			seq.nSkipped++
+			continue
+		}
+		seq.lastErr = &JSONArrayError{index, int(seq.dec.InputOffset()), err}
+		return nil, seq.lastErr
+	}
+}
+
+// End the sequence with err
+func (seq *JSONArraySeq[T]) fail(err error) (*T, error) {
+	seq.isDone = true
+	seq.lastErr = err
+	return nil, err
+}
@@ -0,0 +1,102 @@
+package seq
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const eventsJSON = `{
+  "meta": {"items": "not these", "pages": [1, 2]},
+  "data": {
+    "count": 3,
+    "items": [
+      {"name": "Rex", "count": 1},
+      {"name": "Fido", "count": "two"},
+      {"name": "Zoë", "count": 3, "tags": ["cat"]}
+    ]
+  }
+}`
+
+func TestJSONArraySeq(t *testing.T) {
+	input := `[1, 2,3 ]`
+	sq := NewJSONArraySeq[int](strings.NewReader(input))
+	vals := []int{}
+	for val := range Iter(sq) {
+		vals = append(vals, *val)
+	}
+	assert.Equal(t, []int{1, 2, 3}, vals)
+	assert.Equal(t, io.EOF, sq.Err())
+	assert.Equal(t, len(input), sq.Position())
+}
+
+func TestJSONArraySeqPath(t *testing.T) {
+	sq := NewJSONArraySeqPath[event](strings.NewReader(eventsJSON), ".data.items").SetMalformedPolicy(MalformedSkip)
+	events := collectSeq[*event](t, sq)
+	assert.Len(t, events, 2)
+	assert.Equal(t, event{"Zoë", 3, []string{"cat"}}, *events[1])
+	assert.Equal(t, 1, sq.Skipped())
+}
+
+func TestJSONArraySeqPosition(t *testing.T) {
+	sq := NewJSONArraySeq[string](strings.NewReader(`["Rex","Fido"]`))
+	val, err := sq.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, "Rex", *val)
+	assert.Equal(t, 1, sq.LastPosition())
+	assert.Equal(t, 6, sq.Position())
+	sq.Next()
+	assert.Equal(t, 6, sq.LastPosition())
+	assert.Equal(t, 13, sq.Position())
+}
+
+func TestJSONArraySeqErrors(t *testing.T) {
+	sq := NewJSONArraySeqPath[event](strings.NewReader(eventsJSON), ".data.items")
+	sq.Next()
+	val, err := sq.Next()
+	assert.Nil(t, val)
+	var arrayErr *JSONArrayError
+	assert.True(t, errors.As(err, &arrayErr))
+	assert.Equal(t, 1, arrayErr.Index)
+	var typeErr *json.UnmarshalTypeError
+	assert.True(t, errors.As(err, &typeErr))
+	// MalformedFail is sticky
+	_, err2 := sq.Next()
+	assert.Equal(t, err, err2)
+
+	sq = NewJSONArraySeqPath[event](strings.NewReader(eventsJSON), ".data.items").SetMalformedPolicy(MalformedYield)
+	names := []string{}
+	for range 4 {
+		val, err := sq.Next()
+		if err == nil {
+			names = append(names, val.Name)
+		}
+	}
+	assert.Equal(t, []string{"Rex", "Zoë"}, names)
+	assert.Equal(t, io.EOF, sq.Err())
+
+	sq = NewJSONArraySeq[event](strings.NewReader(`[{"name": "Rex"}, {"name": }]`))
+	sq.Next()
+	_, err = sq.Next()
+	assert.True(t, errors.As(err, &arrayErr))
+	var syntaxErr *json.SyntaxError
+	assert.True(t, errors.As(err, &syntaxErr))
+}
+
+func TestJSONArraySeqBadPath(t *testing.T) {
+	for path, msg := range map[string]string{
+		".data.nope":    "no array at JSON path .data.nope: key not found",
+		".data.count":   "no array at JSON path .data.count: found 3 at byte offset 79",
+		".meta.items.x": "no array at JSON path .meta.items: found \"not these\" at byte offset 33",
+	} {
+		_, err := NewJSONArraySeqPath[event](strings.NewReader(eventsJSON), path).Next()
+		assert.True(t, errors.Is(err, ErrJSONPath), path)
+		assert.EqualError(t, err, msg, path)
+	}
+	_, err := NewJSONArraySeq[int](strings.NewReader(`{"a": 1}`)).Next()
+	assert.EqualError(t, err, "no array at JSON path .: found { at byte offset 1")
+}
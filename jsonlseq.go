@@ -0,0 +1,134 @@
+package seq
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// What JSONLSeq does with a line that can't be decoded
+type MalformedPolicy int
+
+const (
+	// Return a *JSONLError and end the sequence: every later call returns the same error. This is the
+	// default.
+	MalformedFail MalformedPolicy = iota
+	// Skip the line and count it
+	MalformedSkip
+	// Return a *JSONLError for the line, then carry on with the next line
+	MalformedYield
+)
+
+// Error for a value that couldn't be decoded. Line is the line number; Offset is the byte offset of the
+// start of the line, or of the error itself for JSON syntax errors.
+type JSONLError struct {
+	Line   int
+	Offset int
+	Err    error
+}
+
+func (e *JSONLError) Error() string {
+	return fmt.Sprintf("line %d (byte offset %d): %v", e.Line, e.Offset, e.Err)
+}
+
+func (e *JSONLError) Unwrap() error {
+	return e.Err
+}
+
+// Seq of values decoded from JSON Lines (NDJSON) input, one value per line. Values are returned by
+// pointer, so T can be any type json.Unmarshal() can decode into, comparable or not. Blank lines are
+// skipped.
+//
+// Add-ons: HasErr, HasIter, HasPosition
+//
+// Positions are those of the underlying LineSeq: after each call to Next(), LastLocation() is the start
+// of the line the value was decoded from.
+type JSONLSeq[T any] struct {
+	*HasErr
+	*HasIter[*T]
+	*HasPosition
+	lines    *LineSeq
+	policy   MalformedPolicy
+	nSkipped int
+}
+
+// C'tor function
+func NewJSONLSeq[T any](rd io.Reader) *JSONLSeq[T] {
+	lines := NewLineSeq(rd)
+	sq := &JSONLSeq[T]{
+		HasErr:      NewHasErr(),
+		HasPosition: lines.HasPosition,
+		lines:       lines,
+	}
+	// HasIter needs the Seq object so it needs special treatment
+	sq.HasIter = NewHasIter(sq)
+	return sq
+}
+
+// Set the policy for lines that can't be decoded, and return the JSONLSeq
+func (seq *JSONLSeq[T]) SetMalformedPolicy(policy MalformedPolicy) *JSONLSeq[T] {
+	seq.policy = policy
+	return seq
+}
+
+// Number of lines skipped under MalformedSkip
+func (seq *JSONLSeq[T]) Skipped() int {
+	return seq.nSkipped
+}
+
+// Return the next value
+func (seq *JSONLSeq[T]) Next() (*T, error) {
+	// MalformedFail errors are sticky
+	var jsonlErr *JSONLError
+	if errors.As(seq.lastErr, &jsonlErr) && seq.policy == MalformedFail {
+		return nil, seq.lastErr
+	}
+	for {
+		line, err := seq.lines.NextBytes()
+		if err != nil && (len(line) == 0 || !errors.Is(err, io.EOF)) {
+			seq.lastErr = err
+			return nil, err
+		}
+		line = bytes.TrimSuffix(line, []byte{'\r'})
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		val := new(T)
+		errDecode := json.Unmarshal(line, val)
+		if errDecode == nil {
+			seq.lastErr = nil
+			return val, nil
+		}
+		if seq.policy == MalformedSkip {
+			seq.nSkipped++
+			continue
+		}
+		offset := seq.LastPosition()
+		var syntaxErr *json.SyntaxError
+		if errors.As(errDecode, &syntaxErr) {
+			offset += int(syntaxErr.Offset)
+		}
+		seq.lastErr = &JSONLError{seq.LastLocation().Line, offset, errDecode}
+		return nil, seq.lastErr
+	}
+}
+
+// Write each element of sq to wr as a line of JSON, and return the number of lines written. Stops at
+// the end of sq (returning a nil error) or at the first error from sq, encoding or writing.
+func WriteJSONL[T comparable](wr io.Writer, sq Seq[T]) (int, error) {
+	enc := json.NewEncoder(wr)
+	cur := newCursor(sq)
+	n := 0
+	for {
+		cur.advance()
+		if !cur.ok {
+			return n, cur.err
+		}
+		if err := enc.Encode(cur.val); err != nil {
+			return n, err
+		}
+		n++
+	}
+}
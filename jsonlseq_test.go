@@ -0,0 +1,101 @@
+package seq
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type event struct {
+	Name  string   `json:"name"`
+	Count int      `json:"count"`
+	Tags  []string `json:"tags,omitempty"`
+}
+
+const eventsJSONL = `{"name":"Rex","count":1,"tags":["dog"]}
+
+{"name":"Fido","count":
+{"name":"Spot","count":"three"}
+{"name":"Zoë","count":4}`
+
+func TestJSONLSeq(t *testing.T) {
+	sq := NewJSONLSeq[event](strings.NewReader(eventsJSONL))
+	ev, err := sq.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, event{"Rex", 1, []string{"dog"}}, *ev)
+	ev, err = sq.Next()
+	assert.Nil(t, ev)
+	var jsonlErr *JSONLError
+	assert.True(t, errors.As(err, &jsonlErr))
+	assert.Equal(t, 3, jsonlErr.Line)
+	assert.Equal(t, 64, jsonlErr.Offset)
+	var syntaxErr *json.SyntaxError
+	assert.True(t, errors.As(err, &syntaxErr))
+	assert.Equal(t, "line 3 (byte offset 64): unexpected end of JSON input", err.Error())
+	// MalformedFail is sticky
+	_, err2 := sq.Next()
+	assert.Equal(t, err, err2)
+	assert.Equal(t, err, sq.Err())
+}
+
+func TestJSONLSeqSkip(t *testing.T) {
+	sq := NewJSONLSeq[event](strings.NewReader(eventsJSONL)).SetMalformedPolicy(MalformedSkip)
+	events := collectSeq[*event](t, sq)
+	assert.Len(t, events, 2)
+	assert.Equal(t, "Zoë", events[1].Name)
+	assert.Equal(t, 2, sq.Skipped())
+}
+
+func TestJSONLSeqYield(t *testing.T) {
+	sq := NewJSONLSeq[event](strings.NewReader(eventsJSONL)).SetMalformedPolicy(MalformedYield)
+	names := []string{}
+	errLines := []int{}
+	for {
+		ev, err := sq.Next()
+		var jsonlErr *JSONLError
+		if errors.As(err, &jsonlErr) {
+			errLines = append(errLines, jsonlErr.Line)
+			continue
+		}
+		if err != nil {
+			assert.Equal(t, io.EOF, err)
+			break
+		}
+		names = append(names, ev.Name)
+	}
+	assert.Equal(t, []string{"Rex", "Zoë"}, names)
+	assert.Equal(t, []int{3, 4}, errLines)
+}
+
+func TestWriteJSONL(t *testing.T) {
+	var b bytes.Buffer
+	n, err := WriteJSONL(&b, FromValues(Pair[string, int]{"Rex", 1}, Pair[string, int]{"Fido", 2}))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, "{\"First\":\"Rex\",\"Second\":1}\n{\"First\":\"Fido\",\"Second\":2}\n", b.String())
+}
+
+func TestWriteJSONLRoundTrip(t *testing.T) {
+	var b bytes.Buffer
+	sq := NewJSONLSeq[event](strings.NewReader(eventsJSONL)).SetMalformedPolicy(MalformedSkip)
+	n, err := WriteJSONL(&b, sq)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	events := collectSeq[*event](t, NewJSONLSeq[event](&b))
+	assert.Equal(t, []string{"dog"}, events[0].Tags)
+	assert.Equal(t, 4, events[1].Count)
+}
+
+func TestWriteJSONLError(t *testing.T) {
+	errBoom := errors.New("boom")
+	var b bytes.Buffer
+	n, err := WriteJSONL(&b, &errAfterSeq{newTestLineSeq("Rex"), errBoom})
+	assert.Equal(t, 1, n)
+	assert.Equal(t, errBoom, err)
+	assert.Equal(t, "\"Rex\"\n", b.String())
+}
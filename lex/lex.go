@@ -0,0 +1,227 @@
+/* Package `lex` implements a state-function lexer on top of seq.RuneSeq, in the style of Rob Pike's
+ * "Lexical Scanning in Go" talk. A lexer is a set of StateFuncs; each one consumes some runes, emits
+ * zero or more Tokens, and returns the next StateFunc. The Lexer itself is a seq.Seq[Token], so its
+ * output works with Where(), Limit(), Iter() and the rest of the seq package.
+ */
+package lex
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dylt-dev/seq"
+)
+
+// Returned by NextRune() and PeekRune() when there's no more input
+const EOF rune = -1
+
+// Identifies the type of a Token. Values are defined by the user of the package, except for KindError.
+// Start user-defined kinds at 1, so that a Token's zero value doesn't look like a real Token.
+type TokenKind int
+
+// Kind of the Token emitted by Errorf()
+const KindError TokenKind = -1
+
+// A lexeme emitted by a Lexer. Start and End are byte offsets into the input, taken from the RuneSeq's
+// position. Line and Col are 1-based and locate Start; Col counts runes, not bytes. For error tokens,
+// Text is the error message and the position is where the error was detected.
+type Token struct {
+	Kind  TokenKind
+	Text  string
+	Start int
+	End   int
+	Line  int
+	Col   int
+}
+
+func (tok Token) String() string {
+	if tok.Kind == KindError {
+		return fmt.Sprintf("%d:%d: error: %s", tok.Line, tok.Col, tok.Text)
+	}
+	return fmt.Sprintf("%d:%d: %d %q", tok.Line, tok.Col, tok.Kind, tok.Text)
+}
+
+// A state of the lexer. Consumes input, possibly emits Tokens, and returns the next state, or nil to stop.
+type StateFunc func(lx *Lexer) StateFunc
+
+// Seq of Tokens, produced by running StateFuncs over a RuneSeq
+//
+// Add-ons: HasErr, HasIter
+//
+// The Lexer runs its states lazily: each call to Next() runs states until at least one Token has been
+// emitted. Once a state returns nil, Next() returns (Token{}, io.EOF), or the RuneSeq's error if reading
+// failed with something other than io.EOF.
+type Lexer struct {
+	*seq.HasErr
+	*seq.HasIter[Token]
+	input     *seq.Peekable[rune]
+	state     StateFunc
+	runes     []rune
+	start     int
+	startLine int
+	startCol  int
+	queue     []Token
+	isEof     bool
+	readErr   error
+}
+
+// C'tor function
+func NewLexer(rs *seq.RuneSeq, start StateFunc) *Lexer {
+	lx := &Lexer{
+		HasErr:    seq.NewHasErr(),
+		input:     seq.NewPeekable[rune](rs),
+		state:     start,
+		start:     rs.Position(),
+		startLine: 1,
+		startCol:  1,
+	}
+	// HasIter needs the Seq object so it needs special treatment
+	lx.HasIter = seq.NewHasIter(lx)
+	return lx
+}
+
+// Return the next Token, running states as needed
+func (lx *Lexer) Next() (Token, error) {
+	for len(lx.queue) == 0 {
+		if lx.state == nil {
+			err := io.EOF
+			if lx.readErr != nil {
+				err = lx.readErr
+			}
+			lx.SetErr(err)
+			return Token{}, err
+		}
+		lx.state = lx.state(lx)
+	}
+	tok := lx.queue[0]
+	lx.queue = lx.queue[1:]
+	lx.SetErr(nil)
+	return tok, nil
+}
+
+// Consume and return the next rune, or EOF
+func (lx *Lexer) NextRune() rune {
+	ru, err := lx.input.Next()
+	lx.isEof = err != nil
+	if err != nil {
+		if !errors.Is(err, io.EOF) {
+			lx.readErr = err
+		}
+		return EOF
+	}
+	lx.runes = append(lx.runes, ru)
+	return ru
+}
+
+// Return the next rune without consuming it
+func (lx *Lexer) PeekRune() rune {
+	ru, err := lx.input.Peek()
+	if err != nil {
+		return EOF
+	}
+	return ru
+}
+
+// Un-consume the last rune consumed by NextRune(). Can be called repeatedly, back to the start of the
+// current token.
+func (lx *Lexer) Backup() {
+	if len(lx.runes) == 0 {
+		return
+	}
+	ru := lx.runes[len(lx.runes)-1]
+	lx.runes = lx.runes[:len(lx.runes)-1]
+	lx.input.Unread(ru)
+}
+
+// Text of the token consumed so far
+func (lx *Lexer) Current() string {
+	return string(lx.runes)
+}
+
+// Byte offset of the next rune
+func (lx *Lexer) Pos() int {
+	return lx.input.Position()
+}
+
+// Emit the runes consumed so far as a Token of the given kind
+func (lx *Lexer) Emit(kind TokenKind) {
+	lx.queue = append(lx.queue, lx.token(kind, lx.Current()))
+	lx.Ignore()
+}
+
+// Discard the runes consumed so far
+func (lx *Lexer) Ignore() {
+	for _, ru := range lx.runes {
+		if ru == '\n' {
+			lx.startLine++
+			lx.startCol = 1
+		} else {
+			lx.startCol++
+		}
+	}
+	lx.runes = lx.runes[:0]
+	lx.start = lx.Pos()
+}
+
+// Emit an error Token at the current position and return nil, which stops the lexer. Usage from a
+// StateFunc: `return lx.Errorf("unexpected %q", ru)`
+func (lx *Lexer) Errorf(format string, args ...any) StateFunc {
+	// Point at the current position rather than the start of the token
+	line, col := lx.startLine, lx.startCol
+	for _, ru := range lx.runes {
+		if ru == '\n' {
+			line, col = line+1, 1
+		} else {
+			col++
+		}
+	}
+	tok := Token{KindError, fmt.Sprintf(format, args...), lx.Pos(), lx.Pos(), line, col}
+	lx.queue = append(lx.queue, tok)
+	return nil
+}
+
+func (lx *Lexer) token(kind TokenKind, text string) Token {
+	return Token{kind, text, lx.start, lx.Pos(), lx.startLine, lx.startCol}
+}
+
+// Consume the next rune if it's in valid
+func (lx *Lexer) Accept(valid string) bool {
+	if strings.ContainsRune(valid, lx.NextRune()) {
+		return true
+	}
+	lx.backupUnlessEof()
+	return false
+}
+
+// Consume a run of runes from valid, and return how many were consumed
+func (lx *Lexer) AcceptRun(valid string) int {
+	return lx.AcceptRunFunc(func(ru rune) bool { return strings.ContainsRune(valid, ru) })
+}
+
+// Consume a run of runes for which fn returns true, eg unicode.IsDigit, and return how many were consumed
+func (lx *Lexer) AcceptRunFunc(fn func(rune) bool) int {
+	n := 0
+	for {
+		ru := lx.NextRune()
+		if ru == EOF || !fn(ru) {
+			lx.backupUnlessEof()
+			return n
+		}
+		n++
+	}
+}
+
+// Consume runes up to (but not including) the first rune in stop, or to the end of the input. Returns
+// how many were consumed.
+func (lx *Lexer) AcceptUntil(stop string) int {
+	return lx.AcceptRunFunc(func(ru rune) bool { return !strings.ContainsRune(stop, ru) })
+}
+
+// Back up over the last rune, unless NextRune() returned EOF, which isn't part of the token
+func (lx *Lexer) backupUnlessEof() {
+	if !lx.isEof {
+		lx.Backup()
+	}
+}
@@ -0,0 +1,101 @@
+package lex
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/dylt-dev/seq"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	kindIdent TokenKind = iota + 1
+	kindEquals
+	kindNumber
+	kindString
+)
+
+// Lexer for lines like `name = "Rex"` and `age = 3`
+func lexStatement(lx *Lexer) StateFunc {
+	lx.AcceptRun(" \t\n")
+	lx.Ignore()
+	switch ru := lx.PeekRune(); {
+	case ru == EOF:
+		return nil
+	case unicode.IsLetter(ru):
+		lx.AcceptRunFunc(unicode.IsLetter)
+		lx.Emit(kindIdent)
+	case ru == '=':
+		lx.NextRune()
+		lx.Emit(kindEquals)
+	case unicode.IsDigit(ru):
+		lx.AcceptRun("0123456789")
+		lx.Emit(kindNumber)
+	case ru == '"':
+		return lexString
+	default:
+		return lx.Errorf("unexpected %q", ru)
+	}
+	return lexStatement
+}
+
+func lexString(lx *Lexer) StateFunc {
+	lx.Accept(`"`)
+	lx.AcceptUntil("\"\n")
+	if !lx.Accept(`"`) {
+		return lx.Errorf("unterminated string")
+	}
+	lx.Emit(kindString)
+	return lexStatement
+}
+
+func newTestLexer(input string) *Lexer {
+	return NewLexer(seq.NewRuneSeq(strings.NewReader(input)), lexStatement)
+}
+
+func TestLexer(t *testing.T) {
+	lx := newTestLexer("name = \"Zoë\"\nage = 3\n")
+	tokens := []Token{}
+	for tok := range lx.Iter() {
+		tokens = append(tokens, tok)
+	}
+	expected := []Token{
+		{kindIdent, "name", 0, 4, 1, 1},
+		{kindEquals, "=", 5, 6, 1, 6},
+		{kindString, `"Zoë"`, 7, 13, 1, 8},
+		{kindIdent, "age", 14, 17, 2, 1},
+		{kindEquals, "=", 18, 19, 2, 5},
+		{kindNumber, "3", 20, 21, 2, 7},
+	}
+	assert.Equal(t, expected, tokens)
+	assert.True(t, errors.Is(lx.Err(), io.EOF))
+}
+
+func TestLexerError(t *testing.T) {
+	lx := newTestLexer("age = 3\nname @ Rex")
+	tokens := []Token{}
+	for tok := range lx.Iter() {
+		tokens = append(tokens, tok)
+	}
+	last := tokens[len(tokens)-1]
+	assert.Equal(t, KindError, last.Kind)
+	assert.Equal(t, "unexpected '@'", last.Text)
+	assert.Equal(t, 2, last.Line)
+	assert.Equal(t, 6, last.Col)
+	assert.Equal(t, "2:6: error: unexpected '@'", last.String())
+	_, err := lx.Next()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestLexerUnterminatedString(t *testing.T) {
+	lx := newTestLexer(`name = "Rex`)
+	sq := seq.Where(lx, func(tok Token) bool { return tok.Kind == KindError })
+	tok, err := sq.Next()
+	assert.Nil(t, err)
+	assert.Equal(t, "unterminated string", tok.Text)
+	assert.Equal(t, 11, tok.Start)
+	assert.Equal(t, 12, tok.Col)
+}
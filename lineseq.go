@@ -1,68 +1,349 @@
 package seq
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
 	"io"
-	"strings"
+	"unicode/utf8"
 )
 
+// Initial size of LineSeq's buffer. The buffer doubles as needed to hold the longest line.
+const defaultLineBufSize = 4096
+
+// Number of consecutive empty reads after which LineSeq gives up with io.ErrNoProgress
+const maxEmptyReads = 100
+
+// Returned (wrapped, with the line's location) by LineSeq.Next() when a line is longer than
+// LineSeqOptions.MaxLineLength
+var ErrLineTooLong = errors.New("line too long")
+
+// How LineSeq recognizes the end of a line
+type LineEnding int
+
+const (
+	// Lines end with '\n'. A '\r' before the '\n' is left on the line. This is the default.
+	LineEndingLF LineEnding = iota
+	// Lines end with '\n' or "\r\n"
+	LineEndingCRLF
+	// Lines end with '\n', "\r\n" or a lone '\r' (old Mac files)
+	LineEndingAny
+)
+
+// Options for NewLineSeqWithOptions(). The zero value gives the same behavior as NewLineSeq().
+type LineSeqOptions struct {
+	// How lines end. Ignored if Delimiter is set.
+	LineEnding LineEnding
+	// If set, lines end with this string instead, eg "\x00" for `find -print0` output
+	Delimiter string
+	// Include the terminator in each line returned by Next()
+	KeepTerminator bool
+	// Maximum line length in bytes, not counting the terminator. 0 means no limit.
+	MaxLineLength int
+	// Initial size of the read buffer, which grows as needed for long lines. 0 means 4KB.
+	BufferSize int
+	// What to do with lines that aren't valid UTF-8. The default passes them through as-is. With an
+	// Encoding, this also covers malformed input that the DecodingReader replaced with U+FFFD.
+	InvalidUTF8 InvalidUTF8Policy
+	// Text encoding of the input. Anything other than EncodingRaw (the default) decodes the input to
+	// UTF-8 with a DecodingReader; EncodingAuto detects the encoding from the BOM.
+	Encoding Encoding
+	// Compression format of the input. Anything other than CompressionNone (the default) decompresses
+	// the input with a DecompressingReader, before decoding; CompressionAuto detects gzip, bzip2 and
+	// zlib from their magic bytes.
+	Compression Compression
+}
+
 // Seq for consuming a Reader line by line
 //
 // Add-ons: HasErr, HasIter, HasPosition
 //
-// LineSeq uses a RuneSeq internally to consume a Reader rune-by-rune in order to form lines.
-// The main reason for this was simply to indirectly test RuneSeq. A nice side effect is that
-// using RuneSeq and building lines ourselves is potentially less memory-intensive than using
-// bufio.ReadString('\n'), since the latter creates internal buffers of a size we can't control.
-// This is unlikely to be that big a deal in reality, but it's nice to know.
+// Location() tracks line and column as well as byte offset. After each call to Next() the line number
+// of LastLocation() is the line number of the line just returned. If rd has a Name() method (eg *os.File),
+// it's used as the filename; otherwise call SetFilename().
+//
+// Positions always count every byte consumed, including terminators and the bytes of lines that were
+// too long, so they can be used with Checkpoint() regardless of options. The exceptions are a LineSeq with
+// an Encoding or Compression option: its positions count bytes of the decoded, decompressed text, not of
+// the original input, so they can't be used to resume.
+//
+// LineSeq reads rd in large blocks into its own buffer and finds line ends with bytes.IndexByte(), so
+// the cost per line is a slice operation rather than a function call per rune. The buffer starts at 4KB
+// (see LineSeqOptions.BufferSize) and grows to fit the longest line seen (or MaxLineLength, if set).
+// NextBytes() returns lines as slices of the buffer without copying; Next() copies them into strings.
 type LineSeq struct {
 	*HasErr
 	*HasIter[string]
 	*HasPosition
-	rd      io.Reader
-	runeSeq *RuneSeq
+	scanBuf
+	index        int
+	opts         LineSeqOptions
+	nInvalid     int
+	decoder      *DecodingReader
+	decompressor *DecompressingReader
 }
 
 // C'tor last function
 func NewLineSeq(rd io.Reader) *LineSeq {
+	return NewLineSeqWithOptions(rd, LineSeqOptions{})
+}
+
+// C'tor function, for lines that don't simply end in '\n'
+func NewLineSeqWithOptions(rd io.Reader, opts LineSeqOptions) *LineSeq {
 	var sq *LineSeq = &LineSeq{
 		HasErr:      NewHasErr(),
 		HasPosition: NewHasPosition(),
-		rd:          rd,
-		runeSeq:     NewRuneSeq(rd),
+		scanBuf:     scanBuf{rd: rd},
+		opts:        opts,
+	}
+	if opts.Compression != CompressionNone {
+		sq.decompressor = NewDecompressingReader(sq.rd, opts.Compression)
+		sq.rd = sq.decompressor
+	}
+	if opts.Encoding != EncodingRaw {
+		sq.decoder = NewDecodingReader(sq.rd, opts.Encoding)
+		sq.decoder.isTracked = true
+		sq.rd = sq.decoder
+	}
+	if opts.BufferSize > 0 {
+		sq.buf = make([]byte, opts.BufferSize)
+	} else {
+		sq.buf = make([]byte, defaultLineBufSize)
 	}
+	sq.HasPosition.setFilenameFrom(rd)
 	// HasIter needs the Seq object so it needs special treatment
 	sq.HasIter = NewHasIter(sq)
 	return sq
 }
 
-// LineSeq delegates its actual reading to RuneSeq, so Err() delegates and
-// returns the last RuneSeq error. (@todo maybe we can/should drop *HasErr?)
-func (seq *LineSeq) Err() error {
-	return seq.runeSeq.Err()
+// Block buffer over a Reader, for Seqs that scan their input a chunk at a time rather than a rune at a
+// time. The unconsumed data is buf[r:w]; up to keep bytes before it are kept as context.
+type scanBuf struct {
+	rd      io.Reader
+	buf     []byte
+	r       int
+	w       int
+	keep    int
+	readErr error
 }
 
-// Read runes until '\n' or EOF is reached.
-//
-// After EOF is reached, all further calls to Next() will return ("", io.EOF)
-func (seq *LineSeq) Next() (string, error) {
-	b := strings.Builder{}
-	for ru := range Iter(seq.runeSeq) {
-		// Make sure that the RuneSeq actually read something
-		if seq.runeSeq.lastSize > 0 {
-			b.WriteRune(ru)
+// Read more data into the buffer, first sliding unconsumed data to the front, and growing the buffer if
+// it's full. Any error from rd is saved in readErr.
+func (sb *scanBuf) fill() {
+	if from := max(0, sb.r-sb.keep); from > 0 {
+		copy(sb.buf, sb.buf[from:sb.w])
+		sb.w -= from
+		sb.r -= from
+	}
+	if sb.w == len(sb.buf) {
+		buf := make([]byte, 2*len(sb.buf))
+		copy(buf, sb.buf[:sb.w])
+		sb.buf = buf
+	}
+	for range maxEmptyReads {
+		n, err := sb.rd.Read(sb.buf[sb.w:])
+		sb.w += n
+		if err != nil {
+			sb.readErr = err
+			return
+		}
+		if n > 0 {
+			return
+		}
+	}
+	sb.readErr = io.ErrNoProgress
+}
+
+// Number of bytes at the end of an unfinished line that might turn out to be part of the terminator
+func (seq *LineSeq) slack() int {
+	if seq.opts.Delimiter != "" {
+		return len(seq.opts.Delimiter) - 1
+	}
+	if seq.opts.LineEnding == LineEndingLF {
+		return 0
+	}
+	return 1
+}
+
+// Look for a terminator in data, starting at from. If found, returns its index and length. If not,
+// returns the index to resume scanning from once there's more data.
+func (seq *LineSeq) findTerminator(data []byte, from int, atEOF bool) (int, int, bool, int) {
+	if seq.opts.Delimiter != "" {
+		delim := seq.opts.Delimiter
+		if i := bytes.Index(data[from:], []byte(delim)); i >= 0 {
+			return from + i, len(delim), true, 0
+		}
+		// The start of the delimiter might be at the end of data
+		return 0, 0, false, max(from, len(data)-(len(delim)-1))
+	}
+	if seq.opts.LineEnding == LineEndingAny {
+		i := bytes.IndexAny(data[from:], "\r\n")
+		if i < 0 {
+			return 0, 0, false, len(data)
 		}
-		// Terminate either on '\n' or EOF. This correctly files that don't end in '\n'.
-		if ru == '\n' || errors.Is(seq.runeSeq.Err(), io.EOF) {
-			break
+		i += from
+		switch {
+		case data[i] == '\n':
+			return i, 1, true, 0
+		case i+1 < len(data):
+			if data[i+1] == '\n' {
+				return i, 2, true, 0
+			}
+			return i, 1, true, 0
+		case atEOF:
+			return i, 1, true, 0
 		}
-		if seq.runeSeq.Err() != nil {
-			return "", seq.runeSeq.Err()
+		// A '\r' at the end of data might be the start of "\r\n", so wait for more data
+		return 0, 0, false, i
+	}
+	i := bytes.IndexByte(data[from:], '\n')
+	if i < 0 {
+		return 0, 0, false, len(data)
+	}
+	i += from
+	if seq.opts.LineEnding == LineEndingCRLF && i > 0 && data[i-1] == '\r' {
+		return i - 1, 2, true, 0
+	}
+	return i, 1, true, 0
+}
+
+// Consume a line of n bytes (including its terminator) from the buffer and return it
+func (seq *LineSeq) consume(n int) []byte {
+	line := seq.buf[seq.r : seq.r+n]
+	seq.r += n
+	seq.HasPosition.moveBytes(line)
+	return line
+}
+
+func (seq *LineSeq) tooLong() ([]byte, error) {
+	seq.lastErr = fmt.Errorf("%s: %w", seq.LastLocation(), ErrLineTooLong)
+	return nil, seq.lastErr
+}
+
+// Apply the InvalidUTF8 option to a line that's about to be returned. Only lines that are returned
+// count towards Index().
+func (seq *LineSeq) checkUTF8(line []byte, err error) ([]byte, error) {
+	malformed := seq.takeMalformed()
+	if len(malformed) > 0 && seq.opts.InvalidUTF8 != InvalidUTF8PassThrough {
+		// The decoder has already replaced malformed input with U+FFFD
+		if seq.opts.InvalidUTF8 == InvalidUTF8Replace {
+			seq.nInvalid += len(malformed)
+			seq.index++
+			seq.lastErr = err
+			return line, err
+		}
+		seq.nInvalid++
+		seq.lastErr = malformed[0]
+		return nil, seq.lastErr
+	}
+	if seq.opts.InvalidUTF8 == InvalidUTF8PassThrough || utf8.Valid(line) {
+		seq.index++
+		seq.lastErr = err
+		return line, err
+	}
+	if seq.opts.InvalidUTF8 == InvalidUTF8Replace {
+		line, n := replaceInvalidUTF8(line)
+		seq.nInvalid += n
+		seq.index++
+		seq.lastErr = err
+		return line, err
+	}
+	i, size := findInvalidUTF8(line)
+	seq.nInvalid++
+	seq.lastErr = &InvalidUTF8Error{seq.LastPosition() + i, bytes.Clone(line[i : i+size])}
+	return nil, seq.lastErr
+}
+
+// Return the errors for any malformed input the decoder found in what was consumed for this line
+func (seq *LineSeq) takeMalformed() []*DecodeError {
+	if seq.decoder == nil {
+		return nil
+	}
+	end := seq.decoder.nOut - (seq.w - seq.r)
+	return seq.decoder.takeMalformed(end-(seq.Position()-seq.LastPosition()), end)
+}
+
+// Return the encoding of the input: the Encoding option, or for EncodingAuto the detected encoding
+func (seq *LineSeq) Encoding() Encoding {
+	if seq.decoder == nil {
+		return EncodingRaw
+	}
+	return seq.decoder.Encoding()
+}
+
+// Return the compression format of the input: the Compression option, or for CompressionAuto the
+// detected format
+func (seq *LineSeq) Compression() Compression {
+	if seq.decompressor == nil {
+		return CompressionNone
+	}
+	return seq.decompressor.Compression()
+}
+
+// Number of invalid UTF-8 sequences replaced so far (InvalidUTF8Replace), or found (InvalidUTF8Fail)
+func (seq *LineSeq) InvalidCount() int {
+	return seq.nInvalid
+}
+
+// Return the next line as a slice of LineSeq's internal buffer. The slice is only valid until the next
+// call to Next() or NextBytes(); copy it to keep it. Otherwise identical to Next(). (Lines with invalid
+// UTF-8 replaced under InvalidUTF8Replace are new slices, not part of the buffer.)
+func (seq *LineSeq) NextBytes() ([]byte, error) {
+	seq.HasPosition.Update(0)
+	maxLen := seq.opts.MaxLineLength
+	isTooLong := false
+	scanned := 0
+	for {
+		data := seq.buf[seq.r:seq.w]
+		atEOF := seq.readErr != nil
+		i, termLen, isFound, next := seq.findTerminator(data, scanned, atEOF)
+		if isFound {
+			line := seq.consume(i + termLen)
+			if isTooLong || (maxLen > 0 && i > maxLen) {
+				return seq.tooLong()
+			}
+			if seq.opts.KeepTerminator {
+				return seq.checkUTF8(line, nil)
+			}
+			return seq.checkUTF8(line[:i], nil)
+		}
+		scanned = next
+		// Keep memory bounded for overlong lines: only hold on to what might be part of the terminator
+		if maxLen > 0 && len(data) > maxLen+seq.slack() {
+			discard := len(data) - seq.slack()
+			seq.HasPosition.moveBytes(data[:discard])
+			seq.r += discard
+			scanned = max(0, scanned-discard)
+			isTooLong = true
+			continue
+		}
+		if atEOF {
+			if !errors.Is(seq.readErr, io.EOF) {
+				seq.lastErr = seq.readErr
+				return nil, seq.readErr
+			}
+			// Terminate on EOF. This correctly handles files without a final terminator.
+			if len(data) == 0 && !isTooLong {
+				seq.lastErr = io.EOF
+				return nil, io.EOF
+			}
+			line := seq.consume(len(data))
+			if isTooLong || (maxLen > 0 && len(line) > maxLen) {
+				return seq.tooLong()
+			}
+			return seq.checkUTF8(line, io.EOF)
 		}
+		seq.fill()
 	}
-	// A string was succesfully read, so update the position variables
-	seq.HasPosition.Update(b.Len())
-	// Remove the '\n'. Technically this removes all trailing '\n's but since we stop at '\n' that's not a concern.
-	str := strings.TrimRight(b.String(), "\n")
-	return str, seq.runeSeq.Err()
+}
+
+// Return the next line, without its terminator unless KeepTerminator is set.
+//
+// After EOF is reached, all further calls to Next() will return ("", io.EOF). A final line without a
+// terminator is returned along with io.EOF. A line longer than MaxLineLength is consumed in full but
+// not returned; instead Next() returns ("", err) where errors.Is(err, ErrLineTooLong), and the following
+// call carries on with the next line.
+func (seq *LineSeq) Next() (string, error) {
+	line, err := seq.NextBytes()
+	return string(line), err
 }
@@ -1,11 +1,14 @@
 package seq
 
 import (
+	"bufio"
+	"bytes"
 	"errors"
 	"io"
 	"os"
 	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -90,3 +93,219 @@ func TestLineSeqCount(t *testing.T) {
 	assert.Equal(t, 1000, n)
 	testEof(t, sq)
 }
+
+func TestLineSeqLocation(t *testing.T) {
+	f, err := os.Open("./petnames.txt")
+	assert.Nil(t, err)
+	sq := NewLineSeq(f)
+	for range 13 {
+		_, err = sq.Next()
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, "./petnames.txt:13:1", sq.LastLocation().String())
+	assert.Equal(t, 14, sq.Location().Line)
+	assert.Equal(t, sq.Position(), sq.Location().Offset)
+}
+
+func TestLineSeqCrlf(t *testing.T) {
+	// By default the '\r' is left on the line
+	sq := FromString("one\r\ntwo\r\n")
+	line, err := sq.Next()
+	testNextOk(t, "one\r", line, err)
+	sq = NewLineSeqWithOptions(strings.NewReader("one\r\ntwo\r\n"), LineSeqOptions{LineEnding: LineEndingCRLF})
+	assert.Equal(t, []string{"one", "two"}, collectSeq[string](t, sq))
+	assert.Equal(t, 10, sq.Position())
+	assert.Equal(t, 3, sq.LastLocation().Line)
+}
+
+func TestLineSeqAnyLineEnding(t *testing.T) {
+	str := "one\rtwo\r\nthree\nfour\r"
+	sq := NewLineSeqWithOptions(strings.NewReader(str), LineSeqOptions{LineEnding: LineEndingAny})
+	expected := []struct {
+		line string
+		pos  int
+	}{{"one", 4}, {"two", 9}, {"three", 15}, {"four", 20}}
+	for _, exp := range expected {
+		line, err := sq.Next()
+		testNextOk(t, exp.line, line, err)
+		assert.Equal(t, exp.pos, sq.Position())
+	}
+	testEof(t, sq)
+	assert.Equal(t, len(str), sq.Position())
+}
+
+func TestLineSeqDelimiter(t *testing.T) {
+	str := "./a.txt\x00./b c.txt\x00./d.txt"
+	sq := NewLineSeqWithOptions(strings.NewReader(str), LineSeqOptions{Delimiter: "\x00"})
+	var (line string; err error)
+	line, err = sq.Next()
+	testNextOk(t, "./a.txt", line, err)
+	line, err = sq.Next()
+	testNextOk(t, "./b c.txt", line, err)
+	assert.Equal(t, 18, sq.Position())
+	// Last element has no terminator, so it comes with EOF
+	line, err = sq.Next()
+	testNext(t, "./d.txt", line, io.EOF, err)
+	// Multi-character delimiters work too
+	sq = NewLineSeqWithOptions(strings.NewReader("a--b-c--"), LineSeqOptions{Delimiter: "--", KeepTerminator: true})
+	line, err = sq.Next()
+	testNextOk(t, "a--", line, err)
+	line, err = sq.Next()
+	testNextOk(t, "b-c--", line, err)
+}
+
+func TestLineSeqKeepTerminator(t *testing.T) {
+	sq := NewLineSeqWithOptions(strings.NewReader("one\r\ntwo"), LineSeqOptions{LineEnding: LineEndingCRLF, KeepTerminator: true})
+	line, err := sq.Next()
+	testNextOk(t, "one\r\n", line, err)
+	line, err = sq.Next()
+	testNext(t, "two", line, io.EOF, err)
+}
+
+func TestLineSeqMaxLineLength(t *testing.T) {
+	str := "short\n" + strings.Repeat("x", 100) + "\r\nok\r\n"
+	sq := NewLineSeqWithOptions(strings.NewReader(str), LineSeqOptions{LineEnding: LineEndingCRLF, MaxLineLength: 10})
+	var (line string; err error)
+	line, err = sq.Next()
+	testNextOk(t, "short", line, err)
+	// The long line is reported and skipped, with positions still counting all of its bytes
+	line, err = sq.Next()
+	assert.Equal(t, "", line)
+	assert.True(t, errors.Is(err, ErrLineTooLong))
+	assert.True(t, errors.Is(sq.Err(), ErrLineTooLong))
+	assert.Equal(t, "2:1: line too long", err.Error())
+	assert.Equal(t, 108, sq.Position())
+	line, err = sq.Next()
+	testNextOk(t, "ok", line, err)
+	// Exactly MaxLineLength is fine
+	sq = NewLineSeqWithOptions(strings.NewReader("0123456789\n"), LineSeqOptions{MaxLineLength: 10})
+	line, err = sq.Next()
+	testNextOk(t, "0123456789", line, err)
+	// Lines that are too long aren't returned, so they don't count towards Index(), in the checkpoint too
+	sq = NewLineSeqWithOptions(strings.NewReader("ok\n"+strings.Repeat("x", 50)+"\nb\n"), LineSeqOptions{MaxLineLength: 10})
+	line, _ = sq.Next()
+	_, err = sq.Next()
+	assert.True(t, errors.Is(err, ErrLineTooLong))
+	line, err = sq.Next()
+	testNextOk(t, "b", line, err)
+	assert.Equal(t, 2, sq.Index())
+	assert.Equal(t, 2, sq.Checkpoint().Index)
+}
+
+func TestLineSeqNextBytes(t *testing.T) {
+	sq := FromString("one\ntwo")
+	line, err := sq.NextBytes()
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("one"), line)
+	line, err = sq.NextBytes()
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, []byte("two"), line)
+	line, err = sq.NextBytes()
+	assert.Equal(t, io.EOF, err)
+	assert.Empty(t, line)
+	assert.Equal(t, 7, sq.Position())
+}
+
+func TestLineSeqOneByteReader(t *testing.T) {
+	// Every line straddles a read boundary, including "\r\n" pairs and multi-byte delimiters
+	str := "one\r\ntwo\rthree\n"
+	sq := NewLineSeqWithOptions(iotest.OneByteReader(strings.NewReader(str)), LineSeqOptions{LineEnding: LineEndingAny})
+	assert.Equal(t, []string{"one", "two", "three"}, collectSeq[string](t, sq))
+	assert.Equal(t, len(str), sq.Position())
+	sq = NewLineSeqWithOptions(iotest.OneByteReader(strings.NewReader("a::b::c")), LineSeqOptions{Delimiter: "::"})
+	assert.Equal(t, []string{"a", "b", "c"}, collectSeq[string](t, sq))
+}
+
+func TestLineSeqLongLines(t *testing.T) {
+	// Longer than the initial buffer, so the buffer has to grow
+	long := strings.Repeat("x", 3*defaultLineBufSize)
+	sq := FromString("a\n" + long + "\nb\n")
+	assert.Equal(t, []string{"a", long, "b"}, collectSeq[string](t, sq))
+	assert.Equal(t, 3, sq.Index())
+}
+
+func TestLineSeqReadError(t *testing.T) {
+	errBoom := errors.New("boom")
+	sq := NewLineSeq(io.MultiReader(strings.NewReader("one\ntw"), iotest.ErrReader(errBoom)))
+	line, err := sq.Next()
+	testNextOk(t, "one", line, err)
+	line, err = sq.Next()
+	assert.Equal(t, "", line)
+	assert.True(t, errors.Is(err, errBoom))
+	assert.True(t, errors.Is(sq.Err(), errBoom))
+}
+
+// Input for benchmarks: petnames.txt repeated until it's ~8MB
+func benchmarkInput(b *testing.B) []byte {
+	names, err := os.ReadFile("./petnames.txt")
+	if err != nil {
+		b.Fatal(err)
+	}
+	return bytes.Repeat(names, 1000)
+}
+
+func BenchmarkLineSeqNext(b *testing.B) {
+	data := benchmarkInput(b)
+	b.SetBytes(int64(len(data)))
+	for range b.N {
+		sq := NewLineSeq(bytes.NewReader(data))
+		for {
+			if _, err := sq.Next(); err != nil {
+				break
+			}
+		}
+	}
+}
+
+func BenchmarkLineSeqNextBytes(b *testing.B) {
+	data := benchmarkInput(b)
+	b.SetBytes(int64(len(data)))
+	for range b.N {
+		sq := NewLineSeq(bytes.NewReader(data))
+		for {
+			if _, err := sq.NextBytes(); err != nil {
+				break
+			}
+		}
+	}
+}
+
+func BenchmarkBufioScannerText(b *testing.B) {
+	data := benchmarkInput(b)
+	b.SetBytes(int64(len(data)))
+	for range b.N {
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		for scanner.Scan() {
+			_ = scanner.Text()
+		}
+	}
+}
+
+func BenchmarkBufioScannerBytes(b *testing.B) {
+	data := benchmarkInput(b)
+	b.SetBytes(int64(len(data)))
+	for range b.N {
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		for scanner.Scan() {
+			_ = scanner.Bytes()
+		}
+	}
+}
+
+// The previous LineSeq implementation: build each line rune by rune from a RuneSeq
+func BenchmarkRuneSeqLines(b *testing.B) {
+	data := benchmarkInput(b)
+	b.SetBytes(int64(len(data)))
+	for range b.N {
+		sq := NewRuneSeq(bytes.NewReader(data))
+		sb := strings.Builder{}
+		for ru := range Iter(sq) {
+			if ru == '\n' {
+				_ = sb.String()
+				sb = strings.Builder{}
+				continue
+			}
+			sb.WriteRune(ru)
+		}
+	}
+}
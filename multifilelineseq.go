@@ -0,0 +1,136 @@
+package seq
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// A line returned by MultiFileLineSeq, with the file it came from, its line number in that file
+// (counting from 1) and the byte offset of its start
+type SourcedLine struct {
+	Text     string
+	Filename string
+	Line     int
+	Offset   int
+}
+
+// Return the start of the line as a Position, eg for `file:line:col` in messages
+func (l SourcedLine) Location() Position {
+	return Position{l.Filename, l.Offset, l.Line, 1, 1}
+}
+
+// Seq of the lines of several files, one after the other, each annotated with where it came from. Each
+// file is opened when the sequence gets to it and closed when it's done, so only one is open at a time.
+//
+// Add-ons: HasErr, HasIter, HasPosition
+//
+// Positions are those of the current file's LineSeq, so Position() and Location() start again at 0 and
+// line 1 for each file. A final line without a terminator is returned with a nil error, like any other,
+// unless it's the last line of the last file.
+//
+// A file that can't be opened gives its *fs.PathError, and the next call to Next() carries on with the
+// next file; so does an error reading a file, after closing it. Lines longer than MaxLineLength give an
+// error wrapping ErrLineTooLong, as with LineSeq, and lines that aren't valid UTF-8 under InvalidUTF8Fail
+// give the *InvalidUTF8Error wrapped with the line's location. After either, the sequence carries on
+// with the next line.
+type MultiFileLineSeq struct {
+	*HasErr
+	*HasIter[SourcedLine]
+	*HasPosition
+	paths []string
+	opts  LineSeqOptions
+	i     int
+	file  *os.File
+	lines *LineSeq
+}
+
+// C'tor function
+func NewMultiFileLineSeq(paths ...string) *MultiFileLineSeq {
+	return NewMultiFileLineSeqWithOptions(LineSeqOptions{}, paths...)
+}
+
+// C'tor function. The options are used for each file's LineSeq.
+func NewMultiFileLineSeqWithOptions(opts LineSeqOptions, paths ...string) *MultiFileLineSeq {
+	sq := &MultiFileLineSeq{
+		HasErr:      NewHasErr(),
+		HasPosition: NewHasPosition(),
+		paths:       paths,
+		opts:        opts,
+	}
+	// HasIter needs the Seq object so it needs special treatment
+	sq.HasIter = NewHasIter(sq)
+	return sq
+}
+
+// Index in paths of the current file, or len(paths) once they've all been read
+func (seq *MultiFileLineSeq) FileIndex() int {
+	return seq.i
+}
+
+// Close the current file, for a sequence that's abandoned before the end. Files are closed
+// automatically as they're finished.
+func (seq *MultiFileLineSeq) Close() error {
+	if seq.file == nil {
+		return nil
+	}
+	err := seq.file.Close()
+	seq.file, seq.lines = nil, nil
+	return err
+}
+
+// Finish with the current file and move on to the next
+func (seq *MultiFileLineSeq) nextFile() {
+	seq.Close()
+	seq.i++
+}
+
+// Return the next line
+func (seq *MultiFileLineSeq) Next() (SourcedLine, error) {
+	for seq.i < len(seq.paths) {
+		if seq.lines == nil {
+			file, err := os.Open(seq.paths[seq.i])
+			if err != nil {
+				seq.i++
+				seq.lastErr = err
+				return SourcedLine{}, err
+			}
+			seq.file = file
+			seq.lines = NewLineSeqWithOptions(file, seq.opts)
+			seq.lines.SetFilename(seq.paths[seq.i])
+			seq.HasPosition = seq.lines.HasPosition
+		}
+		text, err := seq.lines.Next()
+		if errors.Is(err, ErrLineTooLong) {
+			// Already has the line's location
+			seq.lastErr = err
+			return SourcedLine{}, err
+		}
+		if errors.Is(err, ErrInvalidUTF8) {
+			seq.lastErr = fmt.Errorf("%s: %w", seq.LastLocation(), err)
+			return SourcedLine{}, seq.lastErr
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			seq.nextFile()
+			seq.lastErr = err
+			return SourcedLine{}, err
+		}
+		isLast := err != nil
+		if isLast {
+			seq.nextFile()
+			if text == "" {
+				continue
+			}
+		}
+		line := SourcedLine{text, seq.HasPosition.filename, seq.HasPosition.lastLine, seq.HasPosition.lastPos}
+		if isLast && seq.i == len(seq.paths) {
+			seq.lastErr = io.EOF
+			return line, io.EOF
+		}
+		seq.lastErr = nil
+		return line, nil
+	}
+	seq.lastErr = io.EOF
+	return SourcedLine{}, io.EOF
+}
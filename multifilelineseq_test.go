@@ -0,0 +1,94 @@
+package seq
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMultiFileLineSeq(t *testing.T) {
+	dogs := writeTestFile(t, "dogs.txt", []byte("Rex\nFido\n"))
+	cats := writeTestFile(t, "cats.txt", []byte("Zoë\nTom"))
+	empty := writeTestFile(t, "empty.txt", nil)
+	fish := writeTestFile(t, "fish.txt", []byte("Nemo\n\nDory"))
+	sq := NewMultiFileLineSeq(dogs, cats, empty, fish)
+	var line SourcedLine
+	var err error
+	line, err = sq.Next()
+	testNext(t, SourcedLine{"Rex", dogs, 1, 0}, line, nil, err)
+	line, err = sq.Next()
+	testNext(t, SourcedLine{"Fido", dogs, 2, 4}, line, nil, err)
+	assert.Equal(t, 0, sq.FileIndex())
+	// The unterminated last line of a file that isn't the last
+	line, err = sq.Next()
+	testNext(t, SourcedLine{"Zoë", cats, 1, 0}, line, nil, err)
+	line, err = sq.Next()
+	testNext(t, SourcedLine{"Tom", cats, 2, 5}, line, nil, err)
+	assert.Equal(t, Position{cats, 5, 2, 1, 1}, sq.LastLocation())
+	line, err = sq.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, SourcedLine{"Nemo", fish, 1, 0}, line)
+	assert.Equal(t, fish+":1:1", line.Location().String())
+	assert.Equal(t, 3, sq.FileIndex())
+	line, err = sq.Next()
+	testNext(t, SourcedLine{"", fish, 2, 5}, line, nil, err)
+	line, err = sq.Next()
+	testNext(t, SourcedLine{"Dory", fish, 3, 6}, line, io.EOF, err)
+	testEof(t, sq)
+	assert.Equal(t, 4, sq.FileIndex())
+
+	testEof(t, NewMultiFileLineSeq())
+}
+
+func TestMultiFileLineSeqErrors(t *testing.T) {
+	dogs := writeTestFile(t, "dogs.txt", []byte("Rex\nFidoFidoFido\nSpot\n"))
+	sq := NewMultiFileLineSeqWithOptions(LineSeqOptions{MaxLineLength: 8}, "nope.txt", dogs)
+	line, err := sq.Next()
+	assert.Equal(t, SourcedLine{}, line)
+	var pathErr *fs.PathError
+	assert.True(t, errors.As(err, &pathErr))
+	assert.Equal(t, "nope.txt", pathErr.Path)
+	assert.True(t, errors.Is(sq.Err(), fs.ErrNotExist))
+	line, err = sq.Next()
+	testNext(t, SourcedLine{"Rex", dogs, 1, 0}, line, nil, err)
+	_, err = sq.Next()
+	assert.True(t, errors.Is(err, ErrLineTooLong))
+	line, err = sq.Next()
+	testNext(t, SourcedLine{"Spot", dogs, 3, 17}, line, nil, err)
+	testEof(t, sq)
+
+	// A line that isn't valid UTF-8 doesn't lose the rest of the file
+	pets := writeTestFile(t, "pets.txt", []byte("Rex\nF\xffdo\nSpot\n"))
+	sq = NewMultiFileLineSeqWithOptions(LineSeqOptions{InvalidUTF8: InvalidUTF8Fail}, pets, dogs)
+	line, err = sq.Next()
+	testNext(t, SourcedLine{"Rex", pets, 1, 0}, line, nil, err)
+	_, err = sq.Next()
+	assert.True(t, errors.Is(err, ErrInvalidUTF8))
+	var utf8Err *InvalidUTF8Error
+	assert.True(t, errors.As(err, &utf8Err))
+	assert.Equal(t, 5, utf8Err.Offset)
+	assert.Equal(t, pets+":2:1: invalid UTF-8 at byte offset 5: ff", err.Error())
+	line, err = sq.Next()
+	testNext(t, SourcedLine{"Spot", pets, 3, 9}, line, nil, err)
+	line, err = sq.Next()
+	testNext(t, SourcedLine{"Rex", dogs, 1, 0}, line, nil, err)
+
+	// Abandoned part way
+	sq = NewMultiFileLineSeq(dogs, dogs)
+	sq.Next()
+	assert.NoError(t, sq.Close())
+	assert.NoError(t, sq.Close())
+}
+
+func TestMultiFileLineSeqIter(t *testing.T) {
+	dogs := writeTestFile(t, "dogs.txt", []byte("Rex\nFido\n"))
+	cats := writeTestFile(t, "cats.txt", []byte("Tom\nFelix\n"))
+	found := []string{}
+	for line := range Iter(Where[SourcedLine](NewMultiFileLineSeq(dogs, cats), func(line SourcedLine) bool { return len(line.Text) > 3 })) {
+		found = append(found, line.Location().String()+" "+line.Text)
+	}
+	assert.Equal(t, []string{dogs + ":2:1 Fido", cats + ":2:1 Felix"}, found)
+}
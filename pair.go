@@ -0,0 +1,88 @@
+package seq
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Two values of possibly different types, eg an element from each of two sequences being combined
+type Pair[A comparable, B comparable] struct {
+	First  A
+	Second B
+}
+
+// Identifies one of the two inputs of a two-sequence operation, eg which side an error came from
+type Side int
+
+const (
+	SideNone Side = iota
+	SideLeft
+	SideRight
+	SideBoth
+)
+
+func (side Side) String() string {
+	switch side {
+	case SideLeft:
+		return "left"
+	case SideRight:
+		return "right"
+	case SideBoth:
+		return "both"
+	default:
+		return "none"
+	}
+}
+
+// Error returned by two-sequence operations (Intersect(), Join(), etc), wrapping an error from one of the
+// inputs and recording which one it came from. Use errors.As() to get the Side, or errors.Is() to check
+// the underlying error.
+type SideError struct {
+	Side Side
+	Err  error
+}
+
+func (e *SideError) Error() string {
+	return fmt.Sprintf("%s sequence: %s", e.Side, e.Err.Error())
+}
+
+func (e *SideError) Unwrap() error {
+	return e.Err
+}
+
+// cursor wraps a Seq so that operations over several inputs can look at the current element before
+// deciding whether to consume it. It also normalizes the end of the input: an element returned together
+// with io.EOF (as Limit() does) is kept as a valid element, and any error other than io.EOF is saved in
+// err.
+type cursor[T comparable] struct {
+	sq   Seq[T]
+	val  T
+	ok   bool
+	done bool
+	err  error
+}
+
+func newCursor[T comparable](sq Seq[T]) *cursor[T] {
+	return &cursor[T]{sq: sq}
+}
+
+// Move to the next element. Afterwards ok reports whether val holds an element.
+func (c *cursor[T]) advance() {
+	if c.done {
+		c.val, c.ok = *new(T), false
+		return
+	}
+	val, err := c.sq.Next()
+	if err != nil {
+		c.done = true
+		if !errors.Is(err, io.EOF) {
+			c.err = err
+			c.val, c.ok = *new(T), false
+			return
+		}
+		c.val, c.ok = val, val != *new(T)
+		return
+	}
+	c.val, c.ok = val, true
+}
@@ -0,0 +1,145 @@
+package seq
+
+import "io"
+
+// Implemented by Seqs with the HasPosition add-on
+type positioner interface {
+	Position() int
+	Location() Position
+}
+
+// Maximum number of consumed elements Peekable remembers the positions of, for Unread()
+const maxUnreadHistory = 1024
+
+type peekEntry[T comparable] struct {
+	val  T
+	size int
+	end  Position
+}
+
+// Seq wrapper that adds lookahead and push-back, for hand-written parsers and tokenizers.
+//
+// Add-ons: HasErr, HasIter, HasPosition
+//
+// Peek() and PeekN() read ahead from the inner Seq and buffer what they read. Unread() pushes an
+// element back so that the next call to Next() returns it again. Position reflects what has logically
+// been consumed through Next(), not what has been buffered: if the inner Seq has the HasPosition add-on
+// (eg RuneSeq, LineSeq), each element's size is measured from the inner Seq's position, otherwise each
+// element counts as 1. The line and column of Location() and LastLocation() are copied from the inner
+// Seq in the same way; without HasPosition they stay at 1:1. Unreading an element moves the position
+// back to where that element started, for up to the last 1024 elements returned; beyond that Unread()
+// still works but leaves the position alone.
+type Peekable[T comparable] struct {
+	*HasErr
+	*HasIter[T]
+	*HasPosition
+	inner    *cursor[T]
+	posInner positioner
+	buf      []peekEntry[T]
+	history  []Position
+}
+
+// C'tor function
+func NewPeekable[T comparable](sqInner Seq[T]) *Peekable[T] {
+	sq := &Peekable[T]{
+		HasErr:      NewHasErr(),
+		HasPosition: NewHasPosition(),
+		inner:       newCursor(sqInner),
+	}
+	sq.posInner, _ = sqInner.(positioner)
+	if sq.posInner != nil {
+		loc := sq.posInner.Location()
+		sq.filename = loc.Filename
+		sq.setLocation(loc)
+		sq.setLastLocation(loc)
+	}
+	// HasIter needs the Seq object so it needs special treatment
+	sq.HasIter = NewHasIter(sq)
+	return sq
+}
+
+// Make sure at least n elements are buffered. Returns the inner Seq's error (io.EOF at the end) if
+// there aren't enough.
+func (seq *Peekable[T]) fill(n int) error {
+	for len(seq.buf) < n {
+		before := 0
+		if seq.posInner != nil {
+			before = seq.posInner.Position()
+		}
+		seq.inner.advance()
+		if !seq.inner.ok {
+			if seq.inner.err != nil {
+				return seq.inner.err
+			}
+			return io.EOF
+		}
+		size := 1
+		var end Position
+		if seq.posInner != nil {
+			size = seq.posInner.Position() - before
+			end = seq.posInner.Location()
+		}
+		seq.buf = append(seq.buf, peekEntry[T]{seq.inner.val, size, end})
+	}
+	return nil
+}
+
+// Return the next element and consume it
+func (seq *Peekable[T]) Next() (T, error) {
+	if err := seq.fill(1); err != nil {
+		seq.lastErr = err
+		return *new(T), err
+	}
+	entry := seq.buf[0]
+	seq.buf = seq.buf[1:]
+	seq.history = append(seq.history, seq.Location())
+	if len(seq.history) > 2*maxUnreadHistory {
+		seq.history = append(seq.history[:0], seq.history[len(seq.history)-maxUnreadHistory:]...)
+	}
+	seq.HasPosition.Update(entry.size)
+	if seq.posInner != nil {
+		seq.setLocation(entry.end)
+	}
+	seq.lastErr = nil
+	return entry.val, nil
+}
+
+// Return the next element without consuming it
+func (seq *Peekable[T]) Peek() (T, error) {
+	if err := seq.fill(1); err != nil {
+		return *new(T), err
+	}
+	return seq.buf[0].val, nil
+}
+
+// Return up to k upcoming elements without consuming them. If fewer than k are left, the ones that are
+// left are returned along with the error that ended the inner Seq (normally io.EOF).
+func (seq *Peekable[T]) PeekN(k int) ([]T, error) {
+	err := seq.fill(k)
+	n := min(k, len(seq.buf))
+	vals := make([]T, n)
+	for i := range n {
+		vals[i] = seq.buf[i].val
+	}
+	return vals, err
+}
+
+// Push an element back, so that it's returned by the next call to Next(). Can be called repeatedly:
+// elements come back out in the reverse order they were unread. The position moves back to where the
+// most recently consumed element started.
+func (seq *Peekable[T]) Unread(val T) {
+	size := 0
+	end := seq.Location()
+	if len(seq.history) > 0 {
+		start := seq.history[len(seq.history)-1]
+		seq.history = seq.history[:len(seq.history)-1]
+		size = seq.pos - start.Offset
+		seq.setLocation(start)
+		seq.setLastLocation(start)
+		if len(seq.history) > 0 {
+			seq.setLastLocation(seq.history[len(seq.history)-1])
+		}
+	}
+	seq.buf = append([]peekEntry[T]{{val, size, end}}, seq.buf...)
+	seq.lastErr = nil
+}
@@ -0,0 +1,90 @@
+package seq
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeekableRunes(t *testing.T) {
+	sq := NewPeekable[rune](NewRuneSeq(strings.NewReader("héllo")))
+	var (ru rune; err error)
+	// Peeking doesn't move the position
+	ru, err = sq.Peek()
+	testNextOk(t, 'h', ru, err)
+	runes, err := sq.PeekN(3)
+	assert.Nil(t, err)
+	assert.Equal(t, []rune{'h', 'é', 'l'}, runes)
+	assert.Equal(t, 0, sq.Position())
+	// Consume 'h' and 'é' (2 bytes)
+	ru, err = sq.Next()
+	testNextOk(t, 'h', ru, err)
+	ru, err = sq.Next()
+	testNextOk(t, 'é', ru, err)
+	assert.Equal(t, 1, sq.LastPosition())
+	assert.Equal(t, 3, sq.Position())
+	// Push both back
+	sq.Unread('é')
+	assert.Equal(t, 1, sq.Position())
+	assert.Equal(t, 0, sq.LastPosition())
+	sq.Unread('h')
+	assert.Equal(t, 0, sq.Position())
+	ru, err = sq.Next()
+	testNextOk(t, 'h', ru, err)
+	ru, err = sq.Next()
+	testNextOk(t, 'é', ru, err)
+	assert.Equal(t, 3, sq.Position())
+}
+
+func TestPeekableEof(t *testing.T) {
+	sq := NewPeekable[string](FromValues("a", "b"))
+	vals, err := sq.PeekN(5)
+	assert.Equal(t, []string{"a", "b"}, vals)
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, []string{"a", "b"}, collectSeq[string](t, sq))
+	assert.Equal(t, 2, sq.Position())
+	_, err = sq.Peek()
+	assert.Equal(t, io.EOF, err)
+	// Unread works after EOF too
+	sq.Unread("b")
+	val, err := sq.Next()
+	testNextOk(t, "b", val, err)
+	testEof(t, sq)
+}
+
+func TestPeekableLines(t *testing.T) {
+	sq := NewPeekable[string](FromString("one\ntwo\n"))
+	line, err := sq.Peek()
+	testNextOk(t, "one", line, err)
+	assert.Equal(t, 0, sq.Position())
+	line, err = sq.Next()
+	testNextOk(t, "one", line, err)
+	assert.Equal(t, 4, sq.Position())
+}
+
+func TestPeekableLocation(t *testing.T) {
+	sq := NewPeekable[rune](NewRuneSeq(strings.NewReader("a\nél")))
+	sq.PeekN(4)
+	assert.Equal(t, Position{"", 0, 1, 1, 1}, sq.Location())
+	sq.Next()
+	sq.Next()
+	ru, err := sq.Next()
+	testNextOk(t, 'é', ru, err)
+	assert.Equal(t, Position{"", 2, 2, 1, 1}, sq.LastLocation())
+	assert.Equal(t, Position{"", 4, 2, 2, 3}, sq.Location())
+	sq.Unread('é')
+	assert.Equal(t, Position{"", 2, 2, 1, 1}, sq.Location())
+	assert.Equal(t, Position{"", 1, 1, 2, 2}, sq.LastLocation())
+	sq.Next()
+	ru, err = sq.Next()
+	testNextOk(t, 'l', ru, err)
+	assert.Equal(t, Position{"", 4, 2, 2, 3}, sq.LastLocation())
+	assert.Equal(t, Position{"", 5, 2, 3, 4}, sq.Location())
+
+	lines := NewPeekable[string](FromString("one\ntwo\n"))
+	lines.Next()
+	lines.Next()
+	assert.Equal(t, 2, lines.LastLocation().Line)
+}
@@ -2,7 +2,9 @@ package seq
 
 import (
 	"bufio"
+	"bytes"
 	"io"
+	"unicode/utf8"
 )
 
 // Seq for expressing a file as a sequence of runes. The Seq that started it all!
@@ -12,6 +14,16 @@ import (
 // Runes can be more than one character, so when ReadRune() returns a rune, it also returns
 // the number of bytes in the rune. Using an iterator, this information would be lost. So RuneSeq
 // makes it available via the `LastSize()` method.
+//
+// Add-ons: HasErr, HasIter, HasPosition
+//
+// Location() tracks line and column as well as byte offset. If rd has a Name() method (eg *os.File),
+// it's used as the filename; otherwise call SetFilename().
+//
+// Invalid UTF-8 is passed through as utf8.RuneError by default, which is what bufio.Reader does. Use
+// SetInvalidUTF8Policy() to replace-and-count it or to fail with an *InvalidUTF8Error instead. With
+// NewRuneSeqEncoding(), the policy also covers malformed input that the DecodingReader replaced with
+// U+FFFD, failing with a *DecodeError.
 type RuneSeq struct {
 	*HasErr
 	*HasIter[rune]
@@ -19,30 +31,130 @@ type RuneSeq struct {
 	rd       io.Reader
 	brd      bufio.Reader
 	lastSize int
+	index    int
+	policy   InvalidUTF8Policy
+	nInvalid int
+	decoder  *DecodingReader
 }
 
+// Default size of RuneSeq's bufio.Reader
+const defaultRuneBufSize = 16
+
 // C'tor function
 func NewRuneSeq(rd io.Reader) *RuneSeq {
+	return NewRuneSeqSize(rd, defaultRuneBufSize)
+}
+
+// C'tor function with a custom buffer size. Larger buffers mean fewer reads from rd. Like
+// bufio.NewReaderSize(), sizes below 16 are raised to 16.
+func NewRuneSeqSize(rd io.Reader, size int) *RuneSeq {
 	sq := &RuneSeq{
 		HasErr:      NewHasErr(),
 		HasPosition: NewHasPosition(),
 		rd:          rd,
-		brd:         *bufio.NewReaderSize(rd, 16),
+		brd:         *bufio.NewReaderSize(rd, size),
 		lastSize:    0,
 	}
+	sq.HasPosition.setFilenameFrom(rd)
 	// HasIter needs the Seq object so it needs special treatment
 	sq.HasIter = NewHasIter(sq)
 	return sq
 }
 
+// C'tor function for input in another encoding, which is decoded to UTF-8 by a DecodingReader. Pass
+// EncodingAuto to detect the encoding from the BOM. Positions count bytes of the decoded UTF-8.
+func NewRuneSeqEncoding(rd io.Reader, enc Encoding) *RuneSeq {
+	decoder := NewDecodingReader(rd, enc)
+	sq := NewRuneSeqSize(decoder, defaultRuneBufSize)
+	sq.decoder = decoder
+	sq.decoder.isTracked = true
+	sq.rd = rd
+	sq.HasPosition.setFilenameFrom(rd)
+	return sq
+}
+
+// Return the encoding of the input: EncodingRaw unless created by NewRuneSeqEncoding(), otherwise the
+// given or detected encoding
+func (seq *RuneSeq) Encoding() Encoding {
+	if seq.decoder == nil {
+		return EncodingRaw
+	}
+	return seq.decoder.Encoding()
+}
+
 // Return the next rune in the sequence.
 // Extra fields: last error, last/current position, last rune size in bytes
 // ReadRune() returns (0, io.EOF) upon exhaustion, so Next() doesn't have to do
 // anything special to detect when there's more data. ReadRune() handles it.
 func (seq *RuneSeq) Next() (rune, error) {
 	ru, size, err := seq.brd.ReadRune()
+	if ru == utf8.RuneError && size == 1 {
+		return seq.invalid()
+	}
+	if ru == utf8.RuneError && size > 1 && seq.decoder != nil {
+		end := seq.decoder.nOut - seq.brd.Buffered()
+		if malformed := seq.decoder.takeMalformed(end-size, end); len(malformed) > 0 {
+			return seq.malformed(malformed[0])
+		}
+	}
 	seq.lastSize = size
 	seq.lastErr = err
-	seq.HasPosition.Update(size)
+	seq.HasPosition.UpdateRune(ru, size)
+	if size > 0 {
+		seq.index++
+	}
 	return ru, err
 }
+
+// Set what Next() does with invalid UTF-8. See InvalidUTF8Policy.
+func (seq *RuneSeq) SetInvalidUTF8Policy(policy InvalidUTF8Policy) *RuneSeq {
+	seq.policy = policy
+	return seq
+}
+
+// Number of invalid UTF-8 sequences seen so far, whatever the policy
+func (seq *RuneSeq) InvalidCount() int {
+	return seq.nInvalid
+}
+
+// Handle invalid UTF-8 according to the policy. ReadRune() has just consumed the first bad byte.
+func (seq *RuneSeq) invalid() (rune, error) {
+	seq.nInvalid++
+	if seq.policy == InvalidUTF8PassThrough {
+		seq.index++
+		seq.lastSize = 1
+		seq.lastErr = nil
+		seq.HasPosition.UpdateRune(utf8.RuneError, 1)
+		return utf8.RuneError, nil
+	}
+	// Go back and get the whole invalid sequence, so it's reported and replaced as a unit
+	seq.brd.UnreadRune()
+	peek, _ := seq.brd.Peek(utf8.UTFMax)
+	size := invalidSeqLen(peek)
+	bad := bytes.Clone(peek[:size])
+	seq.brd.Discard(size)
+	offset := seq.Position()
+	seq.lastSize = size
+	seq.HasPosition.UpdateRune(utf8.RuneError, size)
+	if seq.policy == InvalidUTF8Replace {
+		seq.index++
+		seq.lastErr = nil
+		return utf8.RuneError, nil
+	}
+	seq.lastErr = &InvalidUTF8Error{offset, bad}
+	return 0, seq.lastErr
+}
+
+// Handle a U+FFFD that the decoder put in place of malformed input. ReadRune() has already consumed it.
+func (seq *RuneSeq) malformed(err *DecodeError) (rune, error) {
+	seq.nInvalid++
+	seq.lastSize = utf8.RuneLen(utf8.RuneError)
+	seq.HasPosition.UpdateRune(utf8.RuneError, seq.lastSize)
+	if seq.policy != InvalidUTF8Fail {
+		seq.index++
+		seq.lastErr = nil
+		return utf8.RuneError, nil
+	}
+	seq.lastErr = err
+	return 0, seq.lastErr
+}
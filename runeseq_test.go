@@ -26,3 +26,18 @@ func TestRuneSeqCount(t *testing.T) {
 	testEof(t, sq)
 }
 
+
+func TestRuneSeqLocation(t *testing.T) {
+	sq := NewRuneSeq(strings.NewReader("Zoë\nRex"))
+	sq.SetFilename("names.txt")
+	for range 3 {
+		sq.Next()
+	}
+	assert.Equal(t, Position{"names.txt", 4, 1, 4, 5}, sq.Location())
+	assert.Equal(t, "names.txt:1:3", sq.LastLocation().String())
+	// '\n' moves to the next line
+	sq.Next()
+	sq.Next()
+	assert.Equal(t, Position{"names.txt", 6, 2, 2, 2}, sq.Location())
+	assert.Equal(t, "2:1", Position{Line: 2, Col: 1}.String())
+}
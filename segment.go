@@ -0,0 +1,464 @@
+package seq
+
+import (
+	"io"
+	"strings"
+	"unicode"
+)
+
+// Grapheme_Cluster_Break property values (UAX #29)
+type gcbProp int
+
+const (
+	gcbOther gcbProp = iota
+	gcbCR
+	gcbLF
+	gcbControl
+	gcbExtend
+	gcbZWJ
+	gcbRegionalIndicator
+	gcbPrepend
+	gcbSpacingMark
+	gcbL
+	gcbV
+	gcbT
+	gcbLV
+	gcbLVT
+)
+
+// Word_Break property values (UAX #29)
+type wbProp int
+
+const (
+	wbOther wbProp = iota
+	wbCR
+	wbLF
+	wbNewline
+	wbExtend
+	wbZWJ
+	wbRegionalIndicator
+	wbFormat
+	wbKatakana
+	wbHebrewLetter
+	wbALetter
+	wbSingleQuote
+	wbDoubleQuote
+	wbMidNumLet
+	wbMidLetter
+	wbMidNum
+	wbNumeric
+	wbExtendNumLet
+	wbWSegSpace
+)
+
+// Extended_Pictographic isn't in the unicode package, so approximate it with the blocks where emoji live
+func isExtPict(ru rune) bool {
+	switch {
+	case ru == 0x00a9 || ru == 0x00ae || ru == 0x203c || ru == 0x2049 || ru == 0x2122 || ru == 0x2139:
+		return true
+	case ru >= 0x2194 && ru <= 0x21aa, ru >= 0x2300 && ru <= 0x23ff, ru >= 0x2600 && ru <= 0x27bf:
+		return true
+	case ru >= 0x2b00 && ru <= 0x2bff, ru == 0x3030 || ru == 0x303d || ru == 0x3297 || ru == 0x3299:
+		return true
+	case ru >= 0x1f000 && ru <= 0x1f1e5, ru >= 0x1f200 && ru <= 0x1f3fa, ru >= 0x1f400 && ru <= 0x1faff:
+		return true
+	case ru >= 0x1fc00 && ru <= 0x1fffd:
+		return true
+	}
+	return false
+}
+
+// Rough equivalent of Grapheme_Extend: nonspacing and enclosing marks, plus a few special cases
+func isExtend(ru rune) bool {
+	switch {
+	case ru == 0x200c:
+		return true
+	case ru >= 0x1f3fb && ru <= 0x1f3ff:
+		// Emoji skin tone modifiers
+		return true
+	case ru >= 0xe0020 && ru <= 0xe007f:
+		// Tags, used in flag sequences
+		return true
+	case ru == 0xff9e || ru == 0xff9f:
+		return true
+	}
+	return unicode.In(ru, unicode.Mn, unicode.Me)
+}
+
+func isPrepend(ru rune) bool {
+	switch {
+	case ru >= 0x0600 && ru <= 0x0605, ru == 0x06dd, ru == 0x070f, ru == 0x0890 || ru == 0x0891, ru == 0x08e2:
+		return true
+	case ru == 0x110bd || ru == 0x110cd:
+		return true
+	}
+	return false
+}
+
+func graphemeProp(ru rune) gcbProp {
+	switch {
+	case ru == '\r':
+		return gcbCR
+	case ru == '\n':
+		return gcbLF
+	case ru == 0x200d:
+		return gcbZWJ
+	case ru >= 0x1f1e6 && ru <= 0x1f1ff:
+		return gcbRegionalIndicator
+	case isExtend(ru):
+		return gcbExtend
+	case isPrepend(ru):
+		return gcbPrepend
+	case unicode.In(ru, unicode.Cc, unicode.Zl, unicode.Zp, unicode.Cf):
+		return gcbControl
+	case unicode.Is(unicode.Mc, ru):
+		return gcbSpacingMark
+	// Hangul jamo and syllables
+	case ru >= 0x1100 && ru <= 0x115f, ru >= 0xa960 && ru <= 0xa97c:
+		return gcbL
+	case ru >= 0x1160 && ru <= 0x11a7, ru >= 0xd7b0 && ru <= 0xd7c6:
+		return gcbV
+	case ru >= 0x11a8 && ru <= 0x11ff, ru >= 0xd7cb && ru <= 0xd7fb:
+		return gcbT
+	case ru >= 0xac00 && ru <= 0xd7a3:
+		if (ru-0xac00)%28 == 0 {
+			return gcbLV
+		}
+		return gcbLVT
+	}
+	return gcbOther
+}
+
+func wordProp(ru rune) wbProp {
+	switch {
+	case ru == '\r':
+		return wbCR
+	case ru == '\n':
+		return wbLF
+	case ru == 0x0b || ru == 0x0c || ru == 0x85 || ru == 0x2028 || ru == 0x2029:
+		return wbNewline
+	case ru == 0x200d:
+		return wbZWJ
+	case ru >= 0x1f1e6 && ru <= 0x1f1ff:
+		return wbRegionalIndicator
+	case isExtend(ru) || unicode.Is(unicode.Mc, ru):
+		return wbExtend
+	case unicode.Is(unicode.Cf, ru):
+		return wbFormat
+	case ru == '\'':
+		return wbSingleQuote
+	case ru == '"':
+		return wbDoubleQuote
+	case strings.ContainsRune(".‘’․﹒＇．", ru):
+		return wbMidNumLet
+	case strings.ContainsRune(":··՟״‧︓﹕：", ru):
+		return wbMidLetter
+	case strings.ContainsRune(",;;։،؍٬߸⁄︐︔﹐﹔，；", ru):
+		return wbMidNum
+	case unicode.Is(unicode.Nd, ru) && !(ru >= 0xff10 && ru <= 0xff19):
+		return wbNumeric
+	case unicode.Is(unicode.Pc, ru):
+		return wbExtendNumLet
+	case unicode.Is(unicode.Zs, ru):
+		return wbWSegSpace
+	case unicode.Is(unicode.Katakana, ru) || ru == 0x30fc || ru == 0x3031 || ru == 0x3032:
+		return wbKatakana
+	case unicode.Is(unicode.Hebrew, ru) && unicode.IsLetter(ru):
+		return wbHebrewLetter
+	case unicode.IsLetter(ru) && !unicode.In(ru, unicode.Han, unicode.Hiragana, unicode.Thai, unicode.Lao, unicode.Khmer, unicode.Myanmar):
+		return wbALetter
+	}
+	return wbOther
+}
+
+// segmenter holds what GraphemeSeq and WordSeq have in common: a Peekable RuneSeq for lookahead, and
+// positions measured from the RuneSeq, so that invalid bytes and decoded input are counted correctly.
+type segmenter struct {
+	*HasErr
+	*HasPosition
+	runes *Peekable[rune]
+	b     strings.Builder
+}
+
+func newSegmenter(rs *RuneSeq) *segmenter {
+	sg := &segmenter{
+		HasErr:      NewHasErr(),
+		HasPosition: NewHasPosition(),
+		runes:       NewPeekable[rune](rs),
+	}
+	sg.HasPosition.SetFilename(rs.Filename())
+	return sg
+}
+
+// Start a new segment with the first rune, or return the error if there isn't one
+func (sg *segmenter) start() (rune, error) {
+	sg.HasPosition.Update(0)
+	sg.b.Reset()
+	ru, err := sg.runes.Next()
+	if err != nil {
+		sg.lastErr = err
+		return 0, err
+	}
+	sg.add(ru)
+	return ru, nil
+}
+
+// Return the next rune without consuming it. ok is false at the end of the input (or on an error, which
+// is reported when the next segment is started).
+func (sg *segmenter) peek() (rune, bool) {
+	ru, err := sg.runes.Peek()
+	return ru, err == nil
+}
+
+// Consume the rune returned by peek() and add it to the segment
+func (sg *segmenter) consume() {
+	ru, _ := sg.runes.Next()
+	sg.add(ru)
+}
+
+func (sg *segmenter) add(ru rune) {
+	sg.b.WriteRune(ru)
+	sg.HasPosition.move(ru, sg.runes.Position()-sg.runes.LastPosition())
+}
+
+func (sg *segmenter) segment() (string, error) {
+	sg.lastErr = nil
+	return sg.b.String(), nil
+}
+
+// Seq of user-perceived characters: extended grapheme clusters, as defined by Unicode UAX #29. A
+// grapheme cluster can be several runes, eg "e" followed by a combining diaeresis, an emoji with a skin
+// tone modifier, or a flag made from two regional indicators.
+//
+// Add-ons: HasErr, HasIter, HasPosition
+//
+// The unicode package doesn't include the Grapheme_Cluster_Break and Extended_Pictographic properties,
+// so they're derived from general categories and code point ranges. This matches UAX #29 for the
+// overwhelming majority of text, but the Indic conjunct rule (GB9c) isn't implemented.
+type GraphemeSeq struct {
+	*segmenter
+	*HasIter[string]
+}
+
+// C'tor function
+func NewGraphemeSeq(rd io.Reader) *GraphemeSeq {
+	return NewGraphemeSeqFromRuneSeq(NewRuneSeq(rd))
+}
+
+// C'tor function, for a RuneSeq that's already set up, eg with NewRuneSeqEncoding()
+func NewGraphemeSeqFromRuneSeq(rs *RuneSeq) *GraphemeSeq {
+	sq := &GraphemeSeq{segmenter: newSegmenter(rs)}
+	// HasIter needs the Seq object so it needs special treatment
+	sq.HasIter = NewHasIter(sq)
+	return sq
+}
+
+// Return the next grapheme cluster
+func (seq *GraphemeSeq) Next() (string, error) {
+	first, err := seq.start()
+	if err != nil {
+		return "", err
+	}
+	prev := graphemeProp(first)
+	nRI := 0
+	if prev == gcbRegionalIndicator {
+		nRI = 1
+	}
+	// For GB11: is the cluster so far ExtPict Extend*, or ExtPict Extend* ZWJ?
+	isPict := isExtPict(first)
+	isPictZWJ := false
+	for {
+		ru, ok := seq.peek()
+		if !ok {
+			break
+		}
+		next := graphemeProp(ru)
+		if !graphemeJoin(prev, next, nRI, isPictZWJ, ru) {
+			break
+		}
+		seq.consume()
+		switch {
+		case isExtPict(ru):
+			isPict, isPictZWJ = true, false
+		case next == gcbZWJ:
+			isPictZWJ, isPict = isPict, false
+		case next != gcbExtend:
+			isPict, isPictZWJ = false, false
+		}
+		if next == gcbRegionalIndicator {
+			nRI++
+		} else {
+			nRI = 0
+		}
+		prev = next
+	}
+	return seq.segment()
+}
+
+// Decide whether there's no boundary between two runes, applying rules GB3 to GB13
+func graphemeJoin(prev, next gcbProp, nRI int, isPictZWJ bool, ru rune) bool {
+	switch {
+	case prev == gcbCR && next == gcbLF:
+		return true
+	case prev == gcbControl || prev == gcbCR || prev == gcbLF:
+		return false
+	case next == gcbControl || next == gcbCR || next == gcbLF:
+		return false
+	case prev == gcbL && (next == gcbL || next == gcbV || next == gcbLV || next == gcbLVT):
+		return true
+	case (prev == gcbLV || prev == gcbV) && (next == gcbV || next == gcbT):
+		return true
+	case (prev == gcbLVT || prev == gcbT) && next == gcbT:
+		return true
+	case next == gcbExtend || next == gcbZWJ || next == gcbSpacingMark || prev == gcbPrepend:
+		return true
+	case prev == gcbZWJ && isPictZWJ && isExtPict(ru):
+		return true
+	case prev == gcbRegionalIndicator && next == gcbRegionalIndicator:
+		return nRI%2 == 1
+	}
+	return false
+}
+
+// Seq of word-boundary segments, as defined by Unicode UAX #29. Every rune of the input belongs to
+// exactly one segment, so spaces and punctuation come back as segments too; filter with IsWordLike()
+// to keep only the words. Words like "can't", "3.14" and "snake_case" are kept whole.
+//
+// Add-ons: HasErr, HasIter, HasPosition
+//
+// As with GraphemeSeq, the Word_Break property is derived from general categories and scripts. Scripts
+// that need a dictionary to find word boundaries (Chinese, Japanese kana, Thai, etc) come back one
+// character at a time.
+type WordSeq struct {
+	*segmenter
+	*HasIter[string]
+}
+
+// C'tor function
+func NewWordSeq(rd io.Reader) *WordSeq {
+	return NewWordSeqFromRuneSeq(NewRuneSeq(rd))
+}
+
+// C'tor function, for a RuneSeq that's already set up, eg with NewRuneSeqEncoding()
+func NewWordSeqFromRuneSeq(rs *RuneSeq) *WordSeq {
+	sq := &WordSeq{segmenter: newSegmenter(rs)}
+	// HasIter needs the Seq object so it needs special treatment
+	sq.HasIter = NewHasIter(sq)
+	return sq
+}
+
+// Report whether a segment from WordSeq is a word, ie contains a letter or a digit
+func IsWordLike(segment string) bool {
+	return strings.IndexFunc(segment, func(ru rune) bool { return unicode.IsLetter(ru) || unicode.IsNumber(ru) }) >= 0
+}
+
+// Property of the first rune after the one about to be considered, skipping Extend, Format and ZWJ (WB4)
+func (seq *WordSeq) lookahead() wbProp {
+	for k := 2; k < 32; k++ {
+		runes, _ := seq.runes.PeekN(k)
+		if len(runes) < k {
+			return wbOther
+		}
+		if prop := wordProp(runes[k-1]); !isWordIgnored(prop) {
+			return prop
+		}
+	}
+	return wbOther
+}
+
+func isWordIgnored(prop wbProp) bool {
+	return prop == wbExtend || prop == wbFormat || prop == wbZWJ
+}
+
+func isNewline(prop wbProp) bool {
+	return prop == wbCR || prop == wbLF || prop == wbNewline
+}
+
+// Return the next segment
+func (seq *WordSeq) Next() (string, error) {
+	first, err := seq.start()
+	if err != nil {
+		return "", err
+	}
+	// prev and prevPrev skip ignored runes (WB4); last doesn't
+	last := wordProp(first)
+	prev, prevPrev := last, wbOther
+	nRI := 0
+	if prev == wbRegionalIndicator {
+		nRI = 1
+	}
+	for {
+		ru, ok := seq.peek()
+		if !ok {
+			break
+		}
+		next := wordProp(ru)
+		isJoin := false
+		isIgnored := false
+		switch {
+		case last == wbCR && next == wbLF:
+			isJoin = true
+		case isNewline(last) || isNewline(next):
+			isJoin = false
+		case last == wbZWJ && isExtPict(ru):
+			isJoin = true
+		case last == wbWSegSpace && next == wbWSegSpace:
+			isJoin = true
+		case isWordIgnored(next):
+			isJoin, isIgnored = true, true
+		default:
+			isJoin = wordJoin(prevPrev, prev, next, seq.lookahead(), nRI)
+		}
+		if !isJoin {
+			break
+		}
+		seq.consume()
+		last = next
+		if !isIgnored {
+			prevPrev, prev = prev, next
+			if next == wbRegionalIndicator {
+				nRI++
+			} else {
+				nRI = 0
+			}
+		}
+	}
+	return seq.segment()
+}
+
+// Decide whether there's no boundary between prev and next, applying rules WB5 to WB16
+func wordJoin(prevPrev, prev, next, after wbProp, nRI int) bool {
+	isAHLetter := func(prop wbProp) bool { return prop == wbALetter || prop == wbHebrewLetter }
+	isMidNumLetQ := func(prop wbProp) bool { return prop == wbMidNumLet || prop == wbSingleQuote }
+	switch {
+	case isAHLetter(prev) && isAHLetter(next):
+		return true
+	case isAHLetter(prev) && (next == wbMidLetter || isMidNumLetQ(next)) && isAHLetter(after):
+		return true
+	case isAHLetter(prevPrev) && (prev == wbMidLetter || isMidNumLetQ(prev)) && isAHLetter(next):
+		return true
+	case prev == wbHebrewLetter && next == wbSingleQuote:
+		return true
+	case prev == wbHebrewLetter && next == wbDoubleQuote && after == wbHebrewLetter:
+		return true
+	case prevPrev == wbHebrewLetter && prev == wbDoubleQuote && next == wbHebrewLetter:
+		return true
+	case prev == wbNumeric && next == wbNumeric:
+		return true
+	case isAHLetter(prev) && next == wbNumeric, prev == wbNumeric && isAHLetter(next):
+		return true
+	case prevPrev == wbNumeric && (prev == wbMidNum || isMidNumLetQ(prev)) && next == wbNumeric:
+		return true
+	case prev == wbNumeric && (next == wbMidNum || isMidNumLetQ(next)) && after == wbNumeric:
+		return true
+	case prev == wbKatakana && next == wbKatakana:
+		return true
+	case (isAHLetter(prev) || prev == wbNumeric || prev == wbKatakana || prev == wbExtendNumLet) && next == wbExtendNumLet:
+		return true
+	case prev == wbExtendNumLet && (isAHLetter(next) || next == wbNumeric || next == wbKatakana):
+		return true
+	case prev == wbRegionalIndicator && next == wbRegionalIndicator:
+		return nRI%2 == 1
+	}
+	return false
+}
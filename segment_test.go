@@ -0,0 +1,74 @@
+package seq
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGraphemeSeq(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		// "Zoë" with a combining diaeresis is 4 runes but 3 graphemes
+		{"Zoë", []string{"Z", "o", "ë"}},
+		{"a\r\nb", []string{"a", "\r\n", "b"}},
+		// Emoji with skin tone, ZWJ family, and flags made of regional indicator pairs
+		{"👍🏽👩‍👩‍👧!", []string{"👍🏽", "👩‍👩‍👧", "!"}},
+		{"🇳🇿🇫🇷🇩", []string{"🇳🇿", "🇫🇷", "🇩"}},
+		// Hangul jamo form a single syllable
+		{"각가", []string{"각", "가"}},
+		// Devanagari spacing mark
+		{"नि", []string{"नि"}},
+	}
+	for _, test := range tests {
+		sq := NewGraphemeSeq(strings.NewReader(test.input))
+		assert.Equal(t, test.expected, collectSeq[string](t, sq), test.input)
+	}
+}
+
+func TestGraphemeSeqPosition(t *testing.T) {
+	sq := NewGraphemeSeq(strings.NewReader("Zoë\nRex"))
+	var (g string; err error)
+	for range 3 {
+		g, err = sq.Next()
+	}
+	testNextOk(t, "ë", g, err)
+	assert.Equal(t, 2, sq.LastPosition())
+	assert.Equal(t, 5, sq.Position())
+	sq.Next()
+	g, err = sq.Next()
+	testNextOk(t, "R", g, err)
+	assert.Equal(t, "2:1", sq.LastLocation().String())
+	testEof(t, Skip[string](sq, 2))
+}
+
+func TestWordSeq(t *testing.T) {
+	input := "Can't stop: 3.14 is π, snake_case\tok?"
+	sq := NewWordSeq(strings.NewReader(input))
+	segments := collectSeq[string](t, sq)
+	assert.Equal(t, input, strings.Join(segments, ""))
+	expected := []string{"Can't", " ", "stop", ":", " ", "3.14", " ", "is", " ", "π", ",", " ", "snake_case", "\t", "ok", "?"}
+	assert.Equal(t, expected, segments)
+}
+
+func TestWordSeqIsWordLike(t *testing.T) {
+	sq := NewWordSeq(strings.NewReader("Zoë and  Rex.\n1,000 dogs"))
+	words := collectSeq[string](t, Where(sq, IsWordLike))
+	assert.Equal(t, []string{"Zoë", "and", "Rex", "1,000", "dogs"}, words)
+}
+
+func TestWordSeqPosition(t *testing.T) {
+	sq := NewWordSeq(strings.NewReader("héllo world"))
+	w, err := sq.Next()
+	testNextOk(t, "héllo", w, err)
+	assert.Equal(t, 6, sq.Position())
+	sq.Next()
+	w, err = sq.Next()
+	testNextOk(t, "world", w, err)
+	assert.Equal(t, 7, sq.LastPosition())
+	assert.Equal(t, 7, sq.LastLocation().Col)
+	assert.Equal(t, 8, sq.LastLocation().ByteCol)
+}
@@ -41,6 +41,10 @@ type NextFunc2[T comparable] func() (int, T, error)
 // total bytes received ... it's entirely up to the creator. Users who create Iterators from `Seq`s can check `Seq`
 // custom properties as needed. The only requirement is that the struct's Seq.Next()` method updates those custom 
 // properties as appropriate.
+//
+// Elements must be comparable, so Seqs of slices, maps or structs holding them (eg ChunkSeq, CSVSeq,
+// JSONLSeq) return pointers to them instead. For those Seqs a nil pointer is the empty value, and comes
+// with io.EOF or an error.
 type Seq[T comparable] interface {
 	// Get the next element in the sequence, and an error or nil
 	Next() (T, error)
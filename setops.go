@@ -0,0 +1,197 @@
+package seq
+
+import (
+	"cmp"
+	"io"
+)
+
+type setOp int
+
+const (
+	setOpIntersect setOp = iota
+	setOpUnion
+	setOpExcept
+)
+
+// Hash-based set operation over two sequences. Output is distinct and in input order: elements of
+// the left sequence first, then (for Union) elements of the right sequence.
+type seqSetOp[T comparable] struct {
+	*HasErr
+	op           setOp
+	left         *cursor[T]
+	right        *cursor[T]
+	rightSet     map[T]struct{}
+	seen         map[T]struct{}
+	isStarted    bool
+	rightStarted bool
+}
+
+func newSeqSetOp[T comparable](left Seq[T], right Seq[T], op setOp) *seqSetOp[T] {
+	return &seqSetOp[T]{
+		HasErr:   NewHasErr(),
+		op:       op,
+		left:     newCursor(left),
+		right:    newCursor(right),
+		rightSet: map[T]struct{}{},
+		seen:     map[T]struct{}{},
+	}
+}
+
+func (sq *seqSetOp[T]) fail(side Side, err error) (T, error) {
+	sq.lastErr = &SideError{side, err}
+	return *new(T), sq.lastErr
+}
+
+func (sq *seqSetOp[T]) Next() (T, error) {
+	// On the first call, Intersect and Except read all of the right sequence into a set
+	if !sq.isStarted {
+		sq.isStarted = true
+		if sq.op != setOpUnion {
+			sq.rightStarted = true
+			for sq.right.advance(); sq.right.ok; sq.right.advance() {
+				sq.rightSet[sq.right.val] = struct{}{}
+			}
+			if sq.right.err != nil {
+				return sq.fail(SideRight, sq.right.err)
+			}
+		}
+		sq.left.advance()
+	}
+	for {
+		var val T
+		if sq.left.ok {
+			val = sq.left.val
+			sq.left.advance()
+			_, inRight := sq.rightSet[val]
+			if (sq.op == setOpIntersect && !inRight) || (sq.op == setOpExcept && inRight) {
+				continue
+			}
+		} else if sq.left.err != nil {
+			return sq.fail(SideLeft, sq.left.err)
+		} else if sq.op == setOpUnion {
+			// Left is exhausted, so Union moves on to the right sequence
+			if !sq.rightStarted {
+				sq.rightStarted = true
+				sq.right.advance()
+			}
+			if sq.right.err != nil {
+				return sq.fail(SideRight, sq.right.err)
+			}
+			if !sq.right.ok {
+				break
+			}
+			val = sq.right.val
+			sq.right.advance()
+		} else {
+			break
+		}
+		if _, isSeen := sq.seen[val]; !isSeen {
+			sq.seen[val] = struct{}{}
+			sq.lastErr = nil
+			return val, nil
+		}
+	}
+	sq.lastErr = io.EOF
+	return *new(T), io.EOF
+}
+
+// Return the distinct elements of left that are also in right. right is read in full on the first call to Next().
+func Intersect[T comparable](left Seq[T], right Seq[T]) *seqSetOp[T] {
+	return newSeqSetOp(left, right, setOpIntersect)
+}
+
+// Return the distinct elements of left followed by the distinct elements of right that weren't in left
+func Union[T comparable](left Seq[T], right Seq[T]) *seqSetOp[T] {
+	return newSeqSetOp(left, right, setOpUnion)
+}
+
+// Return the distinct elements of left that aren't in right. right is read in full on the first call to Next().
+func Except[T comparable](left Seq[T], right Seq[T]) *seqSetOp[T] {
+	return newSeqSetOp(left, right, setOpExcept)
+}
+
+// Sorted-merge set operation over two sequences that are both sorted in ascending order. Only the current
+// element of each input and the last returned element are kept, so memory use is constant. Output is
+// distinct and sorted. Inputs that aren't sorted produce incorrect results.
+type seqSortedSetOp[T cmp.Ordered] struct {
+	*HasErr
+	op        setOp
+	left      *cursor[T]
+	right     *cursor[T]
+	last      T
+	hasLast   bool
+	isStarted bool
+}
+
+func newSeqSortedSetOp[T cmp.Ordered](left Seq[T], right Seq[T], op setOp) *seqSortedSetOp[T] {
+	return &seqSortedSetOp[T]{
+		HasErr: NewHasErr(),
+		op:     op,
+		left:   newCursor(left),
+		right:  newCursor(right),
+	}
+}
+
+func (sq *seqSortedSetOp[T]) Next() (T, error) {
+	if !sq.isStarted {
+		sq.isStarted = true
+		sq.left.advance()
+		sq.right.advance()
+	}
+	for {
+		if sq.left.err != nil {
+			sq.lastErr = &SideError{SideLeft, sq.left.err}
+			return *new(T), sq.lastErr
+		}
+		if sq.right.err != nil {
+			sq.lastErr = &SideError{SideRight, sq.right.err}
+			return *new(T), sq.lastErr
+		}
+		var val T
+		var emit bool
+		switch {
+		case sq.left.ok && sq.right.ok:
+			c := cmp.Compare(sq.left.val, sq.right.val)
+			if c < 0 {
+				val, emit = sq.left.val, sq.op != setOpIntersect
+				sq.left.advance()
+			} else if c > 0 {
+				val, emit = sq.right.val, sq.op == setOpUnion
+				sq.right.advance()
+			} else {
+				// Only advance left, so that duplicates on the left still match this right element
+				val, emit = sq.left.val, sq.op != setOpExcept
+				sq.left.advance()
+			}
+		case sq.left.ok:
+			val, emit = sq.left.val, sq.op != setOpIntersect
+			sq.left.advance()
+		case sq.right.ok && sq.op == setOpUnion:
+			val, emit = sq.right.val, true
+			sq.right.advance()
+		default:
+			sq.lastErr = io.EOF
+			return *new(T), io.EOF
+		}
+		if emit && (!sq.hasLast || val != sq.last) {
+			sq.last, sq.hasLast = val, true
+			sq.lastErr = nil
+			return val, nil
+		}
+	}
+}
+
+// Like Intersect(), but for inputs sorted in ascending order. Streams both inputs with constant memory.
+func IntersectSorted[T cmp.Ordered](left Seq[T], right Seq[T]) *seqSortedSetOp[T] {
+	return newSeqSortedSetOp(left, right, setOpIntersect)
+}
+
+// Like Union(), but for inputs sorted in ascending order. Output is a sorted merge of both inputs.
+func UnionSorted[T cmp.Ordered](left Seq[T], right Seq[T]) *seqSortedSetOp[T] {
+	return newSeqSortedSetOp(left, right, setOpUnion)
+}
+
+// Like Except(), but for inputs sorted in ascending order. Streams both inputs with constant memory.
+func ExceptSorted[T cmp.Ordered](left Seq[T], right Seq[T]) *seqSortedSetOp[T] {
+	return newSeqSortedSetOp(left, right, setOpExcept)
+}
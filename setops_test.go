@@ -0,0 +1,99 @@
+package seq
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Seq that returns an error after returning its lines
+type errAfterSeq struct {
+	sqInner Seq[string]
+	err     error
+}
+
+func (sq *errAfterSeq) Next() (string, error) {
+	val, err := sq.sqInner.Next()
+	if errors.Is(err, io.EOF) {
+		return "", sq.err
+	}
+	return val, err
+}
+
+func newTestLineSeq(lines ...string) Seq[string] {
+	return NewLineSeq(strings.NewReader(strings.Join(lines, "\n") + "\n"))
+}
+
+func collectSeq[T comparable](t *testing.T, sq Seq[T]) []T {
+	vals := []T{}
+	for {
+		val, err := sq.Next()
+		if val != *new(T) || err == nil {
+			vals = append(vals, val)
+		}
+		if err != nil {
+			assert.True(t, errors.Is(err, io.EOF), err)
+			return vals
+		}
+	}
+}
+
+func TestIntersect(t *testing.T) {
+	sq := Intersect(newTestLineSeq("Rex", "Fido", "Spot", "Fido"), newTestLineSeq("Spot", "Fido", "Max"))
+	assert.Equal(t, []string{"Fido", "Spot"}, collectSeq(t, sq))
+	testEof(t, sq)
+}
+
+func TestUnion(t *testing.T) {
+	sq := Union(newTestLineSeq("Rex", "Fido", "Rex"), newTestLineSeq("Spot", "Fido", "Max"))
+	assert.Equal(t, []string{"Rex", "Fido", "Spot", "Max"}, collectSeq(t, sq))
+	testEof(t, sq)
+}
+
+func TestExcept(t *testing.T) {
+	sq := Except(newTestLineSeq("Rex", "Fido", "Spot", "Max"), newTestLineSeq("Spot", "Fido"))
+	assert.Equal(t, []string{"Rex", "Max"}, collectSeq(t, sq))
+}
+
+func TestExceptWithLimit(t *testing.T) {
+	// Limit returns its final element with io.EOF, which must still count as an element
+	sq := Except(Limit(newTestLineSeq("a", "b", "c"), 2), newTestLineSeq("a"))
+	assert.Equal(t, []string{"b"}, collectSeq(t, sq))
+}
+
+func TestIntersectSorted(t *testing.T) {
+	sq := IntersectSorted(newTestLineSeq("a", "b", "b", "d", "e"), newTestLineSeq("b", "c", "d", "d"))
+	assert.Equal(t, []string{"b", "d"}, collectSeq(t, sq))
+	testEof(t, sq)
+}
+
+func TestUnionSorted(t *testing.T) {
+	sq := UnionSorted(newTestLineSeq("a", "c", "c", "e"), newTestLineSeq("b", "c", "d", "f"))
+	assert.Equal(t, []string{"a", "b", "c", "d", "e", "f"}, collectSeq(t, sq))
+}
+
+func TestExceptSorted(t *testing.T) {
+	sq := ExceptSorted(newTestLineSeq("a", "b", "b", "c", "e"), newTestLineSeq("b", "d"))
+	assert.Equal(t, []string{"a", "c", "e"}, collectSeq(t, sq))
+}
+
+func TestSetOpSideErrors(t *testing.T) {
+	errBoom := errors.New("boom")
+	var sideErr *SideError
+	// Error on the right is reported as soon as Intersect reads the right sequence
+	_, err := Intersect(newTestLineSeq("a"), &errAfterSeq{newTestLineSeq("a"), errBoom}).Next()
+	assert.True(t, errors.Is(err, errBoom))
+	assert.True(t, errors.As(err, &sideErr))
+	assert.Equal(t, SideRight, sideErr.Side)
+	// Error on the left comes after the left elements
+	sq := UnionSorted(&errAfterSeq{newTestLineSeq("a"), errBoom}, newTestLineSeq("b"))
+	val, err := sq.Next()
+	testNextOk(t, "a", val, err)
+	_, err = sq.Next()
+	assert.True(t, errors.As(err, &sideErr))
+	assert.Equal(t, SideLeft, sideErr.Side)
+	assert.Equal(t, "left sequence: boom", err.Error())
+}
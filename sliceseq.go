@@ -0,0 +1,70 @@
+package seq
+
+import (
+	"cmp"
+	"io"
+	"slices"
+	"strings"
+)
+
+// Seq over the elements of a slice, in index order
+//
+// Add-ons: HasErr, HasIter, HasPosition
+//
+// Position is the slice index: LastPosition() is the index of the last element returned, and Position()
+// is the index of the next one.
+type SliceSeq[T comparable] struct {
+	*HasErr
+	*HasIter[T]
+	*HasPosition
+	vals []T
+}
+
+// C'tor function. The slice is not copied, so changes to it are visible to elements not yet returned.
+func FromSlice[T comparable](vals []T) *SliceSeq[T] {
+	sq := &SliceSeq[T]{
+		HasErr:      NewHasErr(),
+		HasPosition: NewHasPosition(),
+		vals:        vals,
+	}
+	// HasIter needs the Seq object so it needs special treatment
+	sq.HasIter = NewHasIter(sq)
+	return sq
+}
+
+// Variadic version of FromSlice(). Handy for tests: `FromValues("a", "b", "c")`
+func FromValues[T comparable](vals ...T) *SliceSeq[T] {
+	return FromSlice(vals)
+}
+
+// Return a Seq of (key, value) Pairs in ascending key order. The keys are sorted up front, so later
+// changes to m are not reflected.
+func FromMapSorted[K cmp.Ordered, V comparable](m map[K]V) *SliceSeq[Pair[K, V]] {
+	keys := make([]K, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	pairs := make([]Pair[K, V], len(keys))
+	for i, key := range keys {
+		pairs[i] = Pair[K, V]{key, m[key]}
+	}
+	return FromSlice(pairs)
+}
+
+// Return a LineSeq over the lines of a string
+func FromString(s string) *LineSeq {
+	return NewLineSeq(strings.NewReader(s))
+}
+
+func (seq *SliceSeq[T]) Next() (T, error) {
+	i := seq.Position()
+	if i >= len(seq.vals) {
+		seq.lastPos = i
+		seq.lastErr = io.EOF
+		return *new(T), io.EOF
+	}
+	seq.HasPosition.Update(1)
+	seq.lastErr = nil
+	return seq.vals[i], nil
+}
@@ -0,0 +1,54 @@
+package seq
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromSlice(t *testing.T) {
+	sq := FromSlice([]string{"a", "", "c"})
+	var (val string; err error)
+	val, err = sq.Next()
+	testNextOk(t, "a", val, err)
+	assert.Equal(t, 0, sq.LastPosition())
+	assert.Equal(t, 1, sq.Position())
+	// Empty strings are elements too
+	val, err = sq.Next()
+	testNextOk(t, "", val, err)
+	val, err = sq.Next()
+	testNextOk(t, "c", val, err)
+	val, err = sq.Next()
+	testNextEof(t, val, err)
+	assert.True(t, errors.Is(sq.Err(), io.EOF))
+	testEof(t, sq)
+}
+
+func TestFromValuesWhere(t *testing.T) {
+	sq := Where(FromValues("Abbey", "Rex", "Abel"), func(s string) bool { return strings.HasPrefix(s, "Ab") })
+	assert.Equal(t, []string{"Abbey", "Abel"}, collectSeq(t, sq))
+}
+
+func TestFromValuesIter(t *testing.T) {
+	vals := []int{}
+	for i, val := range FromValues(10, 20, 30).IterWithIndex() {
+		assert.Equal(t, (i+1)*10, val)
+		vals = append(vals, val)
+	}
+	assert.Equal(t, []int{10, 20, 30}, vals)
+}
+
+func TestFromMapSorted(t *testing.T) {
+	sq := FromMapSorted(map[string]int{"c": 3, "a": 1, "b": 2})
+	expected := []Pair[string, int]{{"a", 1}, {"b", 2}, {"c", 3}}
+	assert.Equal(t, expected, collectSeq(t, sq))
+}
+
+func TestFromString(t *testing.T) {
+	sq := FromString("one\ntwo\n")
+	assert.Equal(t, []string{"one", "two"}, collectSeq(t, Where(sq, func(s string) bool { return s != "" })))
+	assert.Equal(t, 8, sq.Position())
+}
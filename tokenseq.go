@@ -0,0 +1,305 @@
+package seq
+
+import (
+	"errors"
+	"io"
+	"regexp"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Default for the longest match RegexSeq is guaranteed to find exactly
+const defaultMaxMatchLength = 4096
+
+// A match found by RegexSeq. Offsets are byte offsets from the start of the input.
+type RegexMatch struct {
+	// Text of the whole match
+	Text string
+	// Text of each group, with the whole match as Groups[0], like regexp.FindSubmatch(). A group that
+	// didn't take part in the match is "".
+	Groups []string
+	// Start and end of each group, in pairs, like regexp.FindSubmatchIndex(). A group that didn't take
+	// part in the match is -1, -1.
+	Offsets []int
+	names   []string
+}
+
+// Return the text of a named group, eg `(?P<year>\d{4})`, or "" if there's no such group
+func (m *RegexMatch) Group(name string) string {
+	for i, groupName := range m.names {
+		if groupName == name && name != "" {
+			return m.Groups[i]
+		}
+	}
+	return ""
+}
+
+// Seq of the matches of a regular expression in a Reader, found without reading the whole Reader into
+// memory. Matches are returned by pointer, since RegexMatch holds slices.
+//
+// Add-ons: HasErr, HasIter, HasPosition
+//
+// After each call to Next(), LastPosition() and LastLocation() are the start of the match and
+// Position() and Location() are the end.
+//
+// The input is searched a block at a time, always keeping maxLen bytes (4KB by default) of lookahead
+// past the start of a match, so matches up to maxLen bytes long are the same ones regexp.FindAll()
+// would find in the whole input. Each search carries on from the end of the previous match with the
+// text before it as context, so `^`, `\A`, `\b` and `\B` behave as they do in the whole input. Longer
+// matches are still found as long as they run to the end of the block (eg `\w+` on a very long word),
+// but a regex like `a.*b` may settle on a shorter match than it would in the whole input.
+type RegexSeq struct {
+	*HasErr
+	*HasIter[*RegexMatch]
+	*HasPosition
+	scanBuf
+	re *regexp.Regexp
+	// re anchored one rune in, for searches where that rune is only context
+	reFrom *regexp.Regexp
+	maxLen int
+	// Offsets from the start of the input of buf[r], of where the next search starts, and of the end
+	// of the previous match (-1 before the first)
+	off     int
+	next    int
+	prevEnd int
+}
+
+// C'tor function
+func NewRegexSeq(rd io.Reader, re *regexp.Regexp) *RegexSeq {
+	return NewRegexSeqMaxLen(rd, re, defaultMaxMatchLength)
+}
+
+// C'tor function, for matches longer than 4KB. maxLen is the longest match that's guaranteed to be
+// found exactly; the buffer is at least twice that size.
+func NewRegexSeqMaxLen(rd io.Reader, re *regexp.Regexp, maxLen int) *RegexSeq {
+	maxLen = max(maxLen, utf8.UTFMax)
+	sq := &RegexSeq{
+		HasErr:      NewHasErr(),
+		HasPosition: NewHasPosition(),
+		scanBuf:     scanBuf{rd: rd, buf: make([]byte, max(2*maxLen, defaultLineBufSize)), keep: utf8.UTFMax},
+		re:          re,
+		// A lazy .* finds the leftmost match, as an unanchored search does
+		reFrom:  regexp.MustCompile(`\A(?s:.)(?s:.)*?(` + re.String() + `)`),
+		maxLen:  maxLen,
+		prevEnd: -1,
+	}
+	sq.HasPosition.setFilenameFrom(rd)
+	// HasIter needs the Seq object so it needs special treatment
+	sq.HasIter = NewHasIter(sq)
+	return sq
+}
+
+// Find the first match starting at or after from (relative to the unconsumed data), with the rune
+// before from as context. Returns its offsets relative to the unconsumed data, or nil.
+func (seq *RegexSeq) find(from int) []int {
+	ctx := 0
+	at := seq.r + from
+	if seq.off+from > 0 {
+		_, ctx = utf8.DecodeLastRune(seq.buf[max(0, at-utf8.UTFMax):at])
+	}
+	window := seq.buf[at-ctx : seq.w]
+	// An ordinary search over the context and the data finds the right match, unless it starts on the
+	// context rune
+	loc := seq.re.FindSubmatchIndex(window)
+	if loc != nil && loc[0] < ctx {
+		// Group 1 is the match of re, and the groups after it are re's groups
+		if loc = seq.reFrom.FindSubmatchIndex(window); loc != nil {
+			loc = loc[2:]
+		}
+	}
+	for i := range loc {
+		if loc[i] >= 0 {
+			loc[i] += from - ctx
+		}
+	}
+	return loc
+}
+
+// Consume the first n bytes of the unconsumed data
+func (seq *RegexSeq) discard(n int) {
+	seq.HasPosition.moveBytes(seq.buf[seq.r : seq.r+n])
+	seq.r += n
+	seq.off += n
+}
+
+// Consume the input up to the end of a match, and return the match
+func (seq *RegexSeq) consume(loc []int) *RegexMatch {
+	data := seq.buf[seq.r:seq.w]
+	start, end := loc[0], loc[1]
+	seq.HasPosition.moveBytes(data[:start])
+	seq.HasPosition.Update(0)
+	seq.HasPosition.moveBytes(data[start:end])
+	m := &RegexMatch{
+		Text:    string(data[start:end]),
+		Groups:  make([]string, len(loc)/2),
+		Offsets: make([]int, len(loc)),
+		names:   seq.re.SubexpNames(),
+	}
+	for i := range m.Groups {
+		if loc[2*i] >= 0 {
+			m.Groups[i] = string(data[loc[2*i]:loc[2*i+1]])
+		}
+	}
+	for i := range loc {
+		m.Offsets[i] = loc[i]
+		if loc[i] >= 0 {
+			m.Offsets[i] += seq.off
+		}
+	}
+	seq.r += end
+	seq.off += end
+	return m
+}
+
+// Discard the rest of the data and return io.EOF, or the read error that ended the input
+func (seq *RegexSeq) stop(data []byte) (*RegexMatch, error) {
+	seq.discard(len(data))
+	seq.HasPosition.Update(0)
+	seq.lastErr = seq.readErr
+	if errors.Is(seq.readErr, io.EOF) {
+		seq.lastErr = io.EOF
+	}
+	return nil, seq.lastErr
+}
+
+// Return the next match
+func (seq *RegexSeq) Next() (*RegexMatch, error) {
+	for {
+		data := seq.buf[seq.r:seq.w]
+		atEOF := errors.Is(seq.readErr, io.EOF)
+		from := seq.next - seq.off
+		var loc []int
+		if from <= len(data) {
+			loc = seq.find(from)
+		}
+		if loc == nil {
+			if seq.readErr != nil {
+				return seq.stop(data)
+			}
+			// No match starts before the last maxLen bytes, so they're all that need to be kept
+			seq.discard(max(0, len(data)-seq.maxLen))
+			seq.next = max(seq.next, seq.off)
+			seq.fill()
+			continue
+		}
+		start, end := loc[0], loc[1]
+		if seq.readErr == nil && (end == len(data) || start+seq.maxLen >= len(data)) {
+			// Not enough lookahead: the match might change with more data
+			seq.discard(max(0, min(start, len(data)-seq.maxLen)))
+			seq.next = max(seq.next, seq.off)
+			seq.fill()
+			continue
+		}
+		if !atEOF && end == len(data) {
+			// A match cut short by a read error isn't returned
+			return seq.stop(data)
+		}
+		// Like regexp.FindAll(): after an empty match, move on a rune, and ignore an empty match right
+		// after the previous match
+		isAccepted := true
+		seq.next = seq.off + end
+		if start == end && start == from {
+			isAccepted = seq.off+start != seq.prevEnd
+			_, size := utf8.DecodeRune(data[end:])
+			seq.next += max(size, 1)
+		}
+		seq.prevEnd = seq.off + end
+		if isAccepted {
+			seq.lastErr = nil
+			return seq.consume(loc), nil
+		}
+	}
+}
+
+// Return a Seq of the pieces of rd separated by sep, which can be any string of bytes, eg "\x00" or
+// "\r\n--boundary\r\n". This is a LineSeq with sep as its Delimiter, so like lines, a separator at the
+// very end of the input doesn't produce an empty final piece. An empty sep leaves Delimiter unset, so
+// the input is split on '\n' like NewLineSeq(), not into characters as strings.Split() would.
+func NewSplitSeq(rd io.Reader, sep string) *LineSeq {
+	return NewLineSeqWithOptions(rd, LineSeqOptions{Delimiter: sep})
+}
+
+// Seq of the whitespace-separated fields of a Reader, like strings.Fields() but streaming. Whitespace
+// is as defined by unicode.IsSpace(), and includes newlines.
+//
+// Add-ons: HasErr, HasIter, HasPosition
+//
+// After each call to Next(), LastPosition() and LastLocation() are the start of the field and
+// Position() and Location() are the end. Fields can be any length; the buffer grows to hold the
+// longest one.
+type FieldsSeq struct {
+	*HasErr
+	*HasIter[string]
+	*HasPosition
+	scanBuf
+}
+
+// C'tor function
+func NewFieldsSeq(rd io.Reader) *FieldsSeq {
+	sq := &FieldsSeq{
+		HasErr:      NewHasErr(),
+		HasPosition: NewHasPosition(),
+		scanBuf:     scanBuf{rd: rd, buf: make([]byte, defaultLineBufSize)},
+	}
+	sq.HasPosition.setFilenameFrom(rd)
+	// HasIter needs the Seq object so it needs special treatment
+	sq.HasIter = NewHasIter(sq)
+	return sq
+}
+
+// Consume the data up to end, and return the field that starts at start
+func (seq *FieldsSeq) field(start int, end int) (string, error) {
+	data := seq.buf[seq.r:seq.w]
+	seq.HasPosition.moveBytes(data[:start])
+	seq.HasPosition.Update(0)
+	seq.HasPosition.moveBytes(data[start:end])
+	seq.r += end
+	seq.lastErr = nil
+	return string(data[start:end]), nil
+}
+
+// Return the next field. The last field is returned with a nil error, and io.EOF follows on the next call.
+func (seq *FieldsSeq) Next() (string, error) {
+	start := -1
+	i := 0
+	for {
+		data := seq.buf[seq.r:seq.w]
+		atEOF := seq.readErr != nil
+		for i < len(data) {
+			// Wait for the rest of a rune that's split across reads
+			if !atEOF && !utf8.FullRune(data[i:]) {
+				break
+			}
+			ru, size := utf8.DecodeRune(data[i:])
+			if unicode.IsSpace(ru) {
+				if start >= 0 {
+					return seq.field(start, i)
+				}
+			} else if start < 0 {
+				start = i
+			}
+			i += size
+		}
+		if atEOF {
+			// A field cut short by a read error isn't returned
+			if start >= 0 && errors.Is(seq.readErr, io.EOF) {
+				return seq.field(start, i)
+			}
+			seq.HasPosition.moveBytes(data)
+			seq.HasPosition.Update(0)
+			seq.r = seq.w
+			seq.lastErr = seq.readErr
+			if errors.Is(seq.readErr, io.EOF) {
+				seq.lastErr = io.EOF
+			}
+			return "", seq.lastErr
+		}
+		// Whitespace before a field doesn't need to be kept
+		if start < 0 {
+			seq.HasPosition.moveBytes(data[:i])
+			seq.r += i
+			i = 0
+		}
+		seq.fill()
+	}
+}
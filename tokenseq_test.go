@@ -0,0 +1,146 @@
+package seq
+
+import (
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func matchTexts(t *testing.T, sq Seq[*RegexMatch]) []string {
+	texts := []string{}
+	for _, m := range collectSeq(t, sq) {
+		texts = append(texts, m.Text)
+	}
+	return texts
+}
+
+func TestRegexSeq(t *testing.T) {
+	re := regexp.MustCompile(`(?P<name>\p{Lu}\pL*)=(\d+)?`)
+	sq := NewRegexSeq(strings.NewReader("Rex=3 fido=4\nSpot= Zoë=12"), re)
+	var (m *RegexMatch; err error)
+	m, err = sq.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, "Rex=3", m.Text)
+	assert.Equal(t, []string{"Rex=3", "Rex", "3"}, m.Groups)
+	assert.Equal(t, []int{0, 5, 0, 3, 4, 5}, m.Offsets)
+	assert.Equal(t, "Rex", m.Group("name"))
+	assert.Equal(t, "", m.Group("nope"))
+	m, err = sq.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Spot=", "Spot", ""}, m.Groups)
+	assert.Equal(t, []int{13, 18, 13, 17, -1, -1}, m.Offsets)
+	assert.Equal(t, "2:1", sq.LastLocation().String())
+	assert.Equal(t, 18, sq.Position())
+	m, err = sq.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, "Zoë=12", m.Text)
+	assert.Equal(t, "2:7", sq.LastLocation().String())
+	m, err = sq.Next()
+	assert.Nil(t, m)
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, 26, sq.Position())
+}
+
+func TestRegexSeqMatchesFindAll(t *testing.T) {
+	input := strings.Repeat("Rex and Fido, 42 dogs;\nZoë: 3.14 x ", 300)
+	for _, expr := range []string{`\w+`, `\d+(\.\d+)?`, `x*`, `[A-Z][a-z]+`, `;\n`, `o`} {
+		re := regexp.MustCompile(expr)
+		expected := re.FindAllString(input, -1)
+		// Small maxLen and one byte reads, so matches straddle blocks and reads
+		sq := NewRegexSeqMaxLen(iotest.OneByteReader(strings.NewReader(input)), re, 16)
+		assert.Equal(t, expected, matchTexts(t, sq), expr)
+		sq = NewRegexSeq(strings.NewReader(input), re)
+		assert.Equal(t, expected, matchTexts(t, sq), expr)
+		assert.Equal(t, len(input), sq.Position())
+	}
+}
+
+func TestRegexSeqAnchors(t *testing.T) {
+	long := strings.Repeat("Rex and Fido, 42 dogs;\nZoë: 3.14 x ", 100)
+	for _, input := range []string{"aaa", "ab cd", "ab\ncd\n", "", long} {
+		for _, expr := range []string{`^a`, `\b\w`, `\B\w`, `\b`, `\B`, `^`, `$`, `\w+$`, `(?m)^\w+`, `(?m)\w$`,
+			`(?m)^`, `(?m)$`, `\Aa*`, `a*\z`, `a*`} {
+			re := regexp.MustCompile(expr)
+			expected := re.FindAllString(input, -1)
+			if expected == nil {
+				expected = []string{}
+			}
+			sq := NewRegexSeqMaxLen(iotest.OneByteReader(strings.NewReader(input)), re, 8)
+			assert.Equal(t, expected, matchTexts(t, sq), "%s in %.10q", expr, input)
+			sq = NewRegexSeq(strings.NewReader(input), re)
+			assert.Equal(t, expected, matchTexts(t, sq), "%s in %.10q", expr, input)
+		}
+	}
+	sq := NewRegexSeq(strings.NewReader("ab cd"), regexp.MustCompile(`\b\w`))
+	m, _ := sq.Next()
+	m, _ = sq.Next()
+	assert.Equal(t, []int{3, 4}, m.Offsets)
+}
+
+func TestRegexSeqLongMatch(t *testing.T) {
+	long := strings.Repeat("x", 3*defaultLineBufSize)
+	sq := NewRegexSeqMaxLen(strings.NewReader("a "+long+" b"), regexp.MustCompile(`\w+`), 8)
+	assert.Equal(t, []string{"a", long, "b"}, matchTexts(t, sq))
+}
+
+func TestRegexSeqReadError(t *testing.T) {
+	errBoom := errors.New("boom")
+	sq := NewRegexSeq(io.MultiReader(strings.NewReader("1 2 345678"), iotest.ErrReader(errBoom)), regexp.MustCompile(`\d+`))
+	assert.Equal(t, []string{"1", "2"}, []string{testRegexNext(t, sq), testRegexNext(t, sq)})
+	// The last match might have gone on, so the error comes instead
+	m, err := sq.Next()
+	assert.Nil(t, m)
+	assert.True(t, errors.Is(err, errBoom))
+	assert.True(t, errors.Is(sq.Err(), errBoom))
+}
+
+func testRegexNext(t *testing.T, sq *RegexSeq) string {
+	m, err := sq.Next()
+	assert.NoError(t, err)
+	return m.Text
+}
+
+func TestSplitSeq(t *testing.T) {
+	sq := NewSplitSeq(iotest.OneByteReader(strings.NewReader("Rex--Fido----Spot--")), "--")
+	assert.Equal(t, []string{"Rex", "Fido", "", "Spot"}, collectSeq[string](t, sq))
+}
+
+func TestFieldsSeq(t *testing.T) {
+	// Includes a no-break space and an em space, which unicode.IsSpace() counts as whitespace
+	input := "  Rex Fido\t\tSpot\n Zoë  Max  "
+	sq := NewFieldsSeq(iotest.OneByteReader(strings.NewReader(input)))
+	assert.Equal(t, strings.Fields(input), collectSeq[string](t, sq))
+	assert.Equal(t, len(input), sq.Position())
+	sq = NewFieldsSeq(strings.NewReader(input))
+	var (field string; err error)
+	for range 4 {
+		field, err = sq.Next()
+	}
+	testNextOk(t, "Zoë", field, err)
+	assert.Equal(t, "2:2", sq.LastLocation().String())
+	assert.Equal(t, 19, sq.LastPosition())
+	assert.Equal(t, 23, sq.Position())
+	field, err = sq.Next()
+	testNextOk(t, "Max", field, err)
+	testEof(t, sq)
+}
+
+func TestFieldsSeqReadError(t *testing.T) {
+	errBoom := errors.New("boom")
+	sq := NewFieldsSeq(io.MultiReader(strings.NewReader("ab cd"), iotest.ErrReader(errBoom)))
+	field, err := sq.Next()
+	testNextOk(t, "ab", field, err)
+	field, err = sq.Next()
+	assert.Equal(t, "", field)
+	assert.True(t, errors.Is(err, errBoom))
+}
+
+func TestFieldsSeqEmpty(t *testing.T) {
+	testEof(t, NewFieldsSeq(strings.NewReader(" \n\t ")))
+	testEof(t, NewFieldsSeq(strings.NewReader("")))
+}
@@ -0,0 +1,339 @@
+package seq
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"slices"
+	"strings"
+)
+
+// Returned (in an *fs.PathError) by WalkSeq for a symlink that leads back to one of its own parent
+// directories, under SymlinkFollow
+var ErrSymlinkLoop = errors.New("symlink loop")
+
+// What WalkSeq does with symbolic links
+type SymlinkPolicy int
+
+const (
+	// Return symlinks as entries, without following them. This is the default.
+	SymlinkReport SymlinkPolicy = iota
+	// Follow symlinks, so that a link to a directory is walked like a directory. Links that lead back to
+	// a directory being walked are reported as errors wrapping ErrSymlinkLoop. Telling that two
+	// directories are the same relies on os.SameFile(), so this only works for os.DirFS() and other FSs
+	// whose FileInfos come from the os package; elsewhere (eg a zip FS) loops aren't detected, and
+	// MaxDepth is the only limit.
+	SymlinkFollow
+	// Leave symlinks out
+	SymlinkSkip
+)
+
+// What WalkSeq does when it can't read a directory or follow a link
+type WalkErrorPolicy int
+
+const (
+	// Return the error and end the sequence: every later call returns the same error. This is the
+	// default.
+	WalkErrorFail WalkErrorPolicy = iota
+	// Leave the entry out and count it
+	WalkErrorSkip
+	// Return the error, then carry on with the next entry
+	WalkErrorYield
+)
+
+// Options for NewWalkSeq(). The zero value walks everything, returning files and directories.
+type WalkOptions struct {
+	// Only return entries that match at least one of these glob patterns (see path.Match). Patterns
+	// without a '/' match the entry's name, eg "*.txt"; patterns with a '/' match its path relative to
+	// root, eg "names/*.txt". Directories are walked whether they match or not.
+	Include []string
+	// Leave out entries that match any of these patterns, which work like Include. Excluded directories
+	// aren't walked.
+	Exclude []string
+	// Don't go more than this many levels below root: 1 means only the entries directly in root. 0 means
+	// no limit.
+	MaxDepth int
+	// Only return files (and other non-directories), not directories
+	FilesOnly bool
+	Symlinks  SymlinkPolicy
+	OnError   WalkErrorPolicy
+}
+
+// An entry found by WalkSeq. Path is in fs.FS form, ie slash-separated and relative to the root of the
+// FS; Depth is 1 for entries directly in the directory being walked.
+type WalkEntry struct {
+	Path  string
+	Name  string
+	Depth int
+	Type  fs.FileMode
+	src   *walkSource
+}
+
+type walkSource struct {
+	fsys fs.FS
+	de   fs.DirEntry
+}
+
+// True for a directory, including a symlink to a directory under SymlinkFollow
+func (e WalkEntry) IsDir() bool {
+	return e.Type.IsDir()
+}
+
+// Return the fs.DirEntry the entry came from
+func (e WalkEntry) DirEntry() fs.DirEntry {
+	return e.src.de
+}
+
+// Return the entry's FileInfo. Under SymlinkFollow this is for the link's target.
+func (e WalkEntry) Info() (fs.FileInfo, error) {
+	isFollowed := e.Type&fs.ModeSymlink == 0 && e.src.de.Type()&fs.ModeSymlink != 0
+	if isFollowed {
+		return fs.Stat(e.src.fsys, e.Path)
+	}
+	return e.src.de.Info()
+}
+
+// Open the entry's file
+func (e WalkEntry) Open() (io.ReadCloser, error) {
+	return e.src.fsys.Open(e.Path)
+}
+
+// Return a LineSeq over the entry's contents, with the entry's path as its filename. The file is opened
+// on the first call to Next(), and closed at the end.
+func (e WalkEntry) Lines() *LineSeq {
+	return e.LinesWithOptions(LineSeqOptions{})
+}
+
+// Like Lines(), with options, eg `LineSeqOptions{Compression: CompressionAuto}` for .gz files
+func (e WalkEntry) LinesWithOptions(opts LineSeqOptions) *LineSeq {
+	sq := NewLineSeqWithOptions(&lazyReader{open: e.Open}, opts)
+	sq.SetFilename(e.Path)
+	return sq
+}
+
+// A directory being walked
+type walkFrame struct {
+	dir     string
+	depth   int
+	info    fs.FileInfo
+	entries []fs.DirEntry
+	isRead  bool
+	i       int
+}
+
+// Seq of the entries in a directory tree, found lazily: each directory is read when the walk gets to
+// it, not up front. Entries within a directory come in name order, and each directory is followed
+// straight away by its contents (depth first, like fs.WalkDir()).
+//
+// Add-ons: HasErr, HasIter
+//
+// If root is a directory, it isn't returned itself, only what's in it. If root is a file, it's the only
+// entry, provided the options let it through; patterns with a '/' match its name. Errors reading
+// directories and following links are *fs.PathErrors, handled according to the OnError option.
+type WalkSeq struct {
+	*HasErr
+	*HasIter[WalkEntry]
+	fsys      fs.FS
+	root      string
+	opts      WalkOptions
+	stack     []*walkFrame
+	isStarted bool
+	isDone    bool
+	nSkipped  int
+}
+
+// C'tor function. Use os.DirFS() to walk the real filesystem, eg NewWalkSeq(os.DirFS("/data"), ".", opts).
+func NewWalkSeq(fsys fs.FS, root string, opts WalkOptions) *WalkSeq {
+	sq := &WalkSeq{
+		HasErr: NewHasErr(),
+		fsys:   fsys,
+		root:   root,
+		opts:   opts,
+	}
+	// HasIter needs the Seq object so it needs special treatment
+	sq.HasIter = NewHasIter(sq)
+	return sq
+}
+
+// Number of errors left out under WalkErrorSkip
+func (seq *WalkSeq) Skipped() int {
+	return seq.nSkipped
+}
+
+// Whether p (with name name) matches any of patterns
+func (seq *WalkSeq) matchAny(patterns []string, p string, name string) (bool, error) {
+	rel := strings.TrimPrefix(p, seq.root+"/")
+	if p == seq.root {
+		rel = name
+	}
+	for _, pattern := range patterns {
+		target := name
+		if strings.Contains(pattern, "/") {
+			target = rel
+		}
+		isMatch, err := path.Match(pattern, target)
+		if err != nil || isMatch {
+			return isMatch, err
+		}
+	}
+	return false, nil
+}
+
+// Check the patterns before starting, so a bad one fails straight away rather than when it's first used
+func (seq *WalkSeq) checkPatterns() error {
+	for _, pattern := range slices.Concat(seq.opts.Include, seq.opts.Exclude) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Whether an entry that isn't excluded should be returned, according to FilesOnly and Include
+func (seq *WalkSeq) isIncluded(p string, name string, isDir bool) (bool, error) {
+	if isDir && seq.opts.FilesOnly {
+		return false, nil
+	}
+	if len(seq.opts.Include) == 0 {
+		return true, nil
+	}
+	return seq.matchAny(seq.opts.Include, p, name)
+}
+
+// Handle an error according to the policy. Returns true if the error should be returned.
+func (seq *WalkSeq) walkError(err error) bool {
+	switch seq.opts.OnError {
+	case WalkErrorSkip:
+		seq.nSkipped++
+		return false
+	case WalkErrorYield:
+		seq.lastErr = err
+	default:
+		seq.isDone = true
+		seq.lastErr = err
+	}
+	return true
+}
+
+// Set up the walk. Returns the root as an entry if it's a file that the options let through.
+func (seq *WalkSeq) start() (WalkEntry, bool, error) {
+	if err := seq.checkPatterns(); err != nil {
+		return WalkEntry{}, false, err
+	}
+	info, err := fs.Stat(seq.fsys, seq.root)
+	if err != nil {
+		return WalkEntry{}, false, err
+	}
+	if !info.IsDir() {
+		name := path.Base(seq.root)
+		if isExcluded, err := seq.matchAny(seq.opts.Exclude, seq.root, name); err != nil || isExcluded {
+			return WalkEntry{}, false, err
+		}
+		if isIncluded, err := seq.isIncluded(seq.root, name, false); err != nil || !isIncluded {
+			return WalkEntry{}, false, err
+		}
+		entry := WalkEntry{seq.root, name, 0, info.Mode().Type(), &walkSource{seq.fsys, fs.FileInfoToDirEntry(info)}}
+		return entry, true, nil
+	}
+	seq.stack = append(seq.stack, &walkFrame{dir: seq.root, info: info})
+	return WalkEntry{}, false, nil
+}
+
+// Return the next entry
+func (seq *WalkSeq) Next() (WalkEntry, error) {
+	if seq.isDone {
+		return WalkEntry{}, seq.lastErr
+	}
+	if !seq.isStarted {
+		seq.isStarted = true
+		entry, isFile, err := seq.start()
+		if err != nil {
+			seq.isDone = true
+			seq.lastErr = err
+			return WalkEntry{}, err
+		}
+		if isFile {
+			seq.lastErr = nil
+			return entry, nil
+		}
+	}
+	for len(seq.stack) > 0 {
+		top := seq.stack[len(seq.stack)-1]
+		if !top.isRead {
+			top.isRead = true
+			entries, err := fs.ReadDir(seq.fsys, top.dir)
+			top.entries = entries
+			if err != nil && seq.walkError(err) {
+				return WalkEntry{}, err
+			}
+		}
+		if top.i >= len(top.entries) {
+			seq.stack = seq.stack[:len(seq.stack)-1]
+			continue
+		}
+		de := top.entries[top.i]
+		top.i++
+		entry, err := seq.visit(top, de)
+		if err != nil {
+			if seq.walkError(err) {
+				return WalkEntry{}, err
+			}
+			continue
+		}
+		if entry.src != nil {
+			seq.lastErr = nil
+			return entry, nil
+		}
+	}
+	seq.isDone = true
+	seq.lastErr = io.EOF
+	return WalkEntry{}, io.EOF
+}
+
+// Decide what to do with a directory entry: walk it if it's a directory, and return it if it's wanted.
+// An entry that isn't wanted comes back as the zero WalkEntry.
+func (seq *WalkSeq) visit(parent *walkFrame, de fs.DirEntry) (WalkEntry, error) {
+	p := path.Join(parent.dir, de.Name())
+	depth := parent.depth + 1
+	if isExcluded, err := seq.matchAny(seq.opts.Exclude, p, de.Name()); err != nil || isExcluded {
+		return WalkEntry{}, err
+	}
+	typ := de.Type()
+	var info fs.FileInfo
+	if typ&fs.ModeSymlink != 0 {
+		switch seq.opts.Symlinks {
+		case SymlinkSkip:
+			return WalkEntry{}, nil
+		case SymlinkFollow:
+			var err error
+			if info, err = fs.Stat(seq.fsys, p); err != nil {
+				return WalkEntry{}, err
+			}
+			typ = info.Mode().Type()
+		}
+	}
+	if typ.IsDir() && (seq.opts.MaxDepth == 0 || depth < seq.opts.MaxDepth) {
+		frame := &walkFrame{dir: p, depth: depth}
+		if seq.opts.Symlinks == SymlinkFollow {
+			if info == nil {
+				var err error
+				if info, err = de.Info(); err != nil {
+					return WalkEntry{}, err
+				}
+			}
+			for _, ancestor := range seq.stack {
+				if os.SameFile(info, ancestor.info) {
+					return WalkEntry{}, &fs.PathError{Op: "walk", Path: p, Err: ErrSymlinkLoop}
+				}
+			}
+			frame.info = info
+		}
+		seq.stack = append(seq.stack, frame)
+	}
+	if isIncluded, err := seq.isIncluded(p, de.Name(), typ.IsDir()); err != nil || !isIncluded {
+		return WalkEntry{}, err
+	}
+	return WalkEntry{p, de.Name(), depth, typ, &walkSource{seq.fsys, de}}, nil
+}
@@ -0,0 +1,179 @@
+package seq
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var petsFS = fstest.MapFS{
+	"README":             {Data: []byte("Pet names\n")},
+	"dogs/names.txt":     {Data: []byte("Rex\nFido\nSpot\n")},
+	"dogs/big/names.txt": {Data: []byte("Bruno\nMax\n")},
+	"cats/names.txt":     {Data: []byte("Zoë\nTom\n")},
+	"cats/names.txt.bak": {Data: []byte("Old\n")},
+	".git/config":        {Data: []byte("[core]\n")},
+}
+
+func walkPaths(t *testing.T, sq *WalkSeq) []string {
+	paths := []string{}
+	for _, entry := range collectSeq[WalkEntry](t, sq) {
+		paths = append(paths, entry.Path)
+	}
+	return paths
+}
+
+func TestWalkSeq(t *testing.T) {
+	sq := NewWalkSeq(petsFS, ".", WalkOptions{})
+	expected := []string{".git", ".git/config", "README", "cats", "cats/names.txt", "cats/names.txt.bak",
+		"dogs", "dogs/big", "dogs/big/names.txt", "dogs/names.txt"}
+	assert.Equal(t, expected, walkPaths(t, sq))
+
+	sq = NewWalkSeq(petsFS, "dogs", WalkOptions{})
+	entry, err := sq.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, WalkEntry{"dogs/big", "big", 1, fs.ModeDir, entry.src}, entry)
+	assert.True(t, entry.IsDir())
+	entry, _ = sq.Next()
+	assert.Equal(t, 2, entry.Depth)
+	info, err := entry.Info()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), info.Size())
+}
+
+func TestWalkSeqOptions(t *testing.T) {
+	sq := NewWalkSeq(petsFS, ".", WalkOptions{Include: []string{"*.txt"}, Exclude: []string{".git"}})
+	assert.Equal(t, []string{"cats/names.txt", "dogs/big/names.txt", "dogs/names.txt"}, walkPaths(t, sq))
+	sq = NewWalkSeq(petsFS, ".", WalkOptions{Include: []string{"dogs/*"}, FilesOnly: true})
+	assert.Equal(t, []string{"dogs/names.txt"}, walkPaths(t, sq))
+	sq = NewWalkSeq(petsFS, ".", WalkOptions{Exclude: []string{"dogs/big", ".*", "*.bak"}, MaxDepth: 2})
+	assert.Equal(t, []string{"README", "cats", "cats/names.txt", "dogs", "dogs/names.txt"}, walkPaths(t, sq))
+	sq = NewWalkSeq(petsFS, "dogs", WalkOptions{MaxDepth: 1})
+	assert.Equal(t, []string{"dogs/big", "dogs/names.txt"}, walkPaths(t, sq))
+	// A file as root
+	sq = NewWalkSeq(petsFS, "dogs/names.txt", WalkOptions{})
+	assert.Equal(t, []string{"dogs/names.txt"}, walkPaths(t, sq))
+	sq = NewWalkSeq(petsFS, "dogs/names.txt", WalkOptions{Include: []string{"*.txt"}, FilesOnly: true})
+	assert.Equal(t, []string{"dogs/names.txt"}, walkPaths(t, sq))
+	sq = NewWalkSeq(petsFS, "README", WalkOptions{Include: []string{"*.txt"}})
+	assert.Equal(t, []string{}, walkPaths(t, sq))
+	sq = NewWalkSeq(petsFS, "cats/names.txt", WalkOptions{Exclude: []string{"names.*"}})
+	assert.Equal(t, []string{}, walkPaths(t, sq))
+	sq = NewWalkSeq(petsFS, "cats/names.txt.bak", WalkOptions{Include: []string{"cats/*"}})
+	assert.Equal(t, []string{}, walkPaths(t, sq))
+	testEof(t, sq)
+
+	_, err := NewWalkSeq(petsFS, ".", WalkOptions{Include: []string{"[x"}}).Next()
+	assert.Equal(t, path.ErrBadPattern, err)
+	_, err = NewWalkSeq(petsFS, "nope", WalkOptions{}).Next()
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+}
+
+func TestWalkSeqGrep(t *testing.T) {
+	found := []string{}
+	files := NewWalkSeq(petsFS, ".", WalkOptions{Include: []string{"*.txt"}, FilesOnly: true})
+	for file := range Iter(files) {
+		lines := file.Lines()
+		for line := range Iter(Where[string](lines, func(line string) bool { return strings.Contains(line, "o") })) {
+			found = append(found, lines.LastLocation().String()+": "+line)
+		}
+	}
+	assert.Equal(t, []string{"cats/names.txt:1:1: Zoë", "cats/names.txt:2:1: Tom", "dogs/big/names.txt:1:1: Bruno",
+		"dogs/names.txt:2:1: Fido", "dogs/names.txt:3:1: Spot"}, found)
+}
+
+// FS whose ReadDir fails for one directory
+type errDirFS struct {
+	fstest.MapFS
+	badDir string
+}
+
+func (fsys errDirFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if name == fsys.badDir {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrPermission}
+	}
+	return fsys.MapFS.ReadDir(name)
+}
+
+func TestWalkSeqErrors(t *testing.T) {
+	fsys := errDirFS{petsFS, "dogs/big"}
+	opts := WalkOptions{FilesOnly: true, Exclude: []string{".git"}}
+	sq := NewWalkSeq(fsys, ".", opts)
+	paths := []string{}
+	var err error
+	for {
+		var entry WalkEntry
+		entry, err = sq.Next()
+		if err != nil {
+			break
+		}
+		paths = append(paths, entry.Path)
+	}
+	assert.Equal(t, []string{"README", "cats/names.txt", "cats/names.txt.bak"}, paths)
+	assert.True(t, errors.Is(err, fs.ErrPermission))
+	_, err2 := sq.Next()
+	assert.Equal(t, err, err2)
+
+	opts.OnError = WalkErrorSkip
+	sq = NewWalkSeq(fsys, ".", opts)
+	assert.Equal(t, []string{"README", "cats/names.txt", "cats/names.txt.bak", "dogs/names.txt"}, walkPaths(t, sq))
+	assert.Equal(t, 1, sq.Skipped())
+
+	opts.OnError = WalkErrorYield
+	sq = NewWalkSeq(fsys, ".", opts)
+	nErrs := 0
+	paths = []string{}
+	for {
+		entry, err := sq.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var pathErr *fs.PathError
+			assert.True(t, errors.As(err, &pathErr))
+			assert.Equal(t, "dogs/big", pathErr.Path)
+			nErrs++
+			continue
+		}
+		paths = append(paths, entry.Path)
+	}
+	assert.Equal(t, 1, nErrs)
+	assert.Equal(t, []string{"README", "cats/names.txt", "cats/names.txt.bak", "dogs/names.txt"}, paths)
+}
+
+func TestWalkSeqSymlinks(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "a", "b"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "a", "b", "pets.txt"), []byte("Rex\n"), 0o644))
+	if err := os.Symlink(filepath.Join(dir, "a", "b"), filepath.Join(dir, "link")); err != nil {
+		t.Skip("symlinks not supported:", err)
+	}
+	// A link back up the tree
+	assert.NoError(t, os.Symlink(filepath.Join(dir, "a"), filepath.Join(dir, "a", "b", "loop")))
+	fsys := os.DirFS(dir)
+
+	sq := NewWalkSeq(fsys, ".", WalkOptions{})
+	assert.Equal(t, []string{"a", "a/b", "a/b/loop", "a/b/pets.txt", "link"}, walkPaths(t, sq))
+	sq = NewWalkSeq(fsys, ".", WalkOptions{Symlinks: SymlinkSkip})
+	assert.Equal(t, []string{"a", "a/b", "a/b/pets.txt"}, walkPaths(t, sq))
+
+	sq = NewWalkSeq(fsys, ".", WalkOptions{Symlinks: SymlinkFollow, OnError: WalkErrorSkip})
+	assert.Equal(t, []string{"a", "a/b", "a/b/pets.txt", "link", "link/loop", "link/pets.txt"}, walkPaths(t, sq))
+	// a/b/loop leads back to a, and link/loop/b is a/b again, which is where link leads
+	assert.Equal(t, 2, sq.Skipped())
+
+	sq = NewWalkSeq(fsys, ".", WalkOptions{Symlinks: SymlinkFollow})
+	sq.Next()
+	sq.Next()
+	_, err := sq.Next()
+	assert.True(t, errors.Is(err, ErrSymlinkLoop))
+	assert.EqualError(t, err, "walk a/b/loop: symlink loop")
+}
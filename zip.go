@@ -0,0 +1,215 @@
+package seq
+
+import (
+	"fmt"
+	"io"
+)
+
+type seqZip[A comparable, B comparable] struct {
+	*HasErr
+	a      *cursor[A]
+	b      *cursor[B]
+	isDone bool
+}
+
+func NewSeqZipWrapper[A comparable, B comparable](a Seq[A], b Seq[B]) *seqZip[A, B] {
+	return &seqZip[A, B]{HasErr: NewHasErr(), a: newCursor(a), b: newCursor(b)}
+}
+
+// Return the next element of each sequence as a Pair. Stops as soon as either sequence ends; the other
+// sequence's extra element, if any, is discarded. After that, neither sequence is read again.
+func (sq *seqZip[A, B]) Next() (Pair[A, B], error) {
+	if sq.isDone {
+		return *new(Pair[A, B]), sq.lastErr
+	}
+	sq.a.advance()
+	if !sq.a.ok {
+		return sq.end(SideLeft, sq.a.err)
+	}
+	sq.b.advance()
+	if !sq.b.ok {
+		return sq.end(SideRight, sq.b.err)
+	}
+	sq.lastErr = nil
+	return Pair[A, B]{sq.a.val, sq.b.val}, nil
+}
+
+func (sq *seqZip[A, B]) end(side Side, err error) (Pair[A, B], error) {
+	sq.isDone = true
+	if err != nil {
+		sq.lastErr = &SideError{side, err}
+	} else {
+		sq.lastErr = io.EOF
+	}
+	return *new(Pair[A, B]), sq.lastErr
+}
+
+// Walk a and b in lockstep, returning a Pair of elements until the shorter sequence ends
+func Zip[A comparable, B comparable](a Seq[A], b Seq[B]) *seqZip[A, B] {
+	return NewSeqZipWrapper(a, b)
+}
+
+// Element of ZipLongest(). Ended reports which sequence has run out and been replaced by its fill value:
+// SideNone while both sequences have elements, SideLeft or SideRight once one of them has ended.
+type ZipLongestPair[A comparable, B comparable] struct {
+	Pair[A, B]
+	Ended Side
+}
+
+type seqZipLongest[A comparable, B comparable] struct {
+	*HasErr
+	a     *cursor[A]
+	b     *cursor[B]
+	fillA A
+	fillB B
+}
+
+func NewSeqZipLongestWrapper[A comparable, B comparable](a Seq[A], b Seq[B], fillA A, fillB B) *seqZipLongest[A, B] {
+	return &seqZipLongest[A, B]{NewHasErr(), newCursor(a), newCursor(b), fillA, fillB}
+}
+
+// Return the next element of each sequence, substituting the fill value for a sequence that has ended.
+// Stops once both sequences have ended.
+func (sq *seqZipLongest[A, B]) Next() (ZipLongestPair[A, B], error) {
+	sq.a.advance()
+	if sq.a.err != nil {
+		sq.lastErr = &SideError{SideLeft, sq.a.err}
+		return *new(ZipLongestPair[A, B]), sq.lastErr
+	}
+	sq.b.advance()
+	if sq.b.err != nil {
+		sq.lastErr = &SideError{SideRight, sq.b.err}
+		return *new(ZipLongestPair[A, B]), sq.lastErr
+	}
+	pair := ZipLongestPair[A, B]{Pair[A, B]{sq.a.val, sq.b.val}, SideNone}
+	switch {
+	case !sq.a.ok && !sq.b.ok:
+		sq.lastErr = io.EOF
+		return *new(ZipLongestPair[A, B]), io.EOF
+	case !sq.a.ok:
+		pair.First, pair.Ended = sq.fillA, SideLeft
+	case !sq.b.ok:
+		pair.Second, pair.Ended = sq.fillB, SideRight
+	}
+	sq.lastErr = nil
+	return pair, nil
+}
+
+// Walk a and b in lockstep until both have ended, filling in for the shorter sequence with fillA or fillB
+func ZipLongest[A comparable, B comparable](a Seq[A], b Seq[B], fillA A, fillB B) *seqZipLongest[A, B] {
+	return NewSeqZipLongestWrapper(a, b, fillA, fillB)
+}
+
+type seqZipN[T comparable] struct {
+	*HasErr
+	cursors []*cursor[T]
+	isDone  bool
+}
+
+func NewSeqZipNWrapper[T comparable](seqs ...Seq[T]) *seqZipN[T] {
+	cursors := make([]*cursor[T], len(seqs))
+	for i, sq := range seqs {
+		cursors[i] = newCursor(sq)
+	}
+	return &seqZipN[T]{HasErr: NewHasErr(), cursors: cursors}
+}
+
+// Return a pointer to a new slice holding the next element of every sequence. Stops as soon as any
+// sequence ends, after which none of the sequences is read again.
+func (sq *seqZipN[T]) Next() (*[]T, error) {
+	if sq.isDone {
+		return nil, sq.lastErr
+	}
+	if len(sq.cursors) == 0 {
+		sq.lastErr = io.EOF
+		return nil, io.EOF
+	}
+	vals := make([]T, len(sq.cursors))
+	for i, c := range sq.cursors {
+		c.advance()
+		if c.err != nil {
+			sq.isDone = true
+			sq.lastErr = fmt.Errorf("sequence %d: %w", i, c.err)
+			return nil, sq.lastErr
+		}
+		if !c.ok {
+			sq.isDone = true
+			sq.lastErr = io.EOF
+			return nil, io.EOF
+		}
+		vals[i] = c.val
+	}
+	sq.lastErr = nil
+	return &vals, nil
+}
+
+// Walk any number of same-typed sequences in lockstep, until the shortest one ends
+func ZipN[T comparable](seqs ...Seq[T]) *seqZipN[T] {
+	return NewSeqZipNWrapper(seqs...)
+}
+
+// Shared state for the two halves of Unzip(). Reading from one half buffers the other half's elements
+// until they're asked for.
+type unzipper[A comparable, B comparable] struct {
+	src    *cursor[Pair[A, B]]
+	queueA []A
+	queueB []B
+}
+
+// Read one Pair from the source and queue both halves. Returns false when the source is done.
+func (u *unzipper[A, B]) pull() bool {
+	u.src.advance()
+	if !u.src.ok {
+		return false
+	}
+	u.queueA = append(u.queueA, u.src.val.First)
+	u.queueB = append(u.queueB, u.src.val.Second)
+	return true
+}
+
+func (u *unzipper[A, B]) end() error {
+	if u.src.err != nil {
+		return u.src.err
+	}
+	return io.EOF
+}
+
+type seqUnzipFirst[A comparable, B comparable] struct {
+	*HasErr
+	u *unzipper[A, B]
+}
+
+func (sq *seqUnzipFirst[A, B]) Next() (A, error) {
+	if len(sq.u.queueA) == 0 && !sq.u.pull() {
+		sq.lastErr = sq.u.end()
+		return *new(A), sq.lastErr
+	}
+	val := sq.u.queueA[0]
+	sq.u.queueA = sq.u.queueA[1:]
+	sq.lastErr = nil
+	return val, nil
+}
+
+type seqUnzipSecond[A comparable, B comparable] struct {
+	*HasErr
+	u *unzipper[A, B]
+}
+
+func (sq *seqUnzipSecond[A, B]) Next() (B, error) {
+	if len(sq.u.queueB) == 0 && !sq.u.pull() {
+		sq.lastErr = sq.u.end()
+		return *new(B), sq.lastErr
+	}
+	val := sq.u.queueB[0]
+	sq.u.queueB = sq.u.queueB[1:]
+	sq.lastErr = nil
+	return val, nil
+}
+
+// Split a sequence of Pairs into a sequence of first elements and a sequence of second elements. The two
+// sequences can be read in any order; elements read by one but not yet by the other are buffered, so
+// reading one sequence to the end buffers the whole of the other.
+func Unzip[A comparable, B comparable](sq Seq[Pair[A, B]]) (*seqUnzipFirst[A, B], *seqUnzipSecond[A, B]) {
+	u := &unzipper[A, B]{src: newCursor(sq)}
+	return &seqUnzipFirst[A, B]{NewHasErr(), u}, &seqUnzipSecond[A, B]{NewHasErr(), u}
+}
@@ -0,0 +1,93 @@
+package seq
+
+import (
+	"errors"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestZipPetnames(t *testing.T) {
+	f, err := os.Open("./petnames.txt")
+	assert.Nil(t, err)
+	sq := Zip(NewLineSeq(f), newTestLineSeq("id0", "id1", "id2"))
+	expected := []Pair[string, string]{{"AJ", "id0"}, {"Abbey", "id1"}, {"Abbie", "id2"}}
+	assert.Equal(t, expected, collectSeq(t, sq))
+	testEof(t, sq)
+}
+
+func TestZipLongest(t *testing.T) {
+	sq := ZipLongest(newTestLineSeq("a", "b", "c"), newTestLineSeq("1"), "-", "?")
+	expected := []ZipLongestPair[string, string]{
+		{Pair[string, string]{"a", "1"}, SideNone},
+		{Pair[string, string]{"b", "?"}, SideRight},
+		{Pair[string, string]{"c", "?"}, SideRight},
+	}
+	assert.Equal(t, expected, collectSeq(t, sq))
+	testEof(t, sq)
+}
+
+func TestZipLongestLeftShorter(t *testing.T) {
+	sq := ZipLongest(newTestLineSeq("a"), newTestLineSeq("1", "2"), "-", "?")
+	val, err := sq.Next()
+	testNextOk(t, ZipLongestPair[string, string]{Pair[string, string]{"a", "1"}, SideNone}, val, err)
+	val, err = sq.Next()
+	testNextOk(t, ZipLongestPair[string, string]{Pair[string, string]{"-", "2"}, SideLeft}, val, err)
+	testEof(t, sq)
+}
+
+func TestZipN(t *testing.T) {
+	sq := ZipN(newTestLineSeq("a", "b"), newTestLineSeq("1", "2", "3"), newTestLineSeq("x", "y"))
+	rows := [][]string{}
+	for row := range Iter(sq) {
+		if row != nil {
+			rows = append(rows, *row)
+		}
+	}
+	assert.Equal(t, [][]string{{"a", "1", "x"}, {"b", "2", "y"}}, rows)
+	assert.True(t, errors.Is(sq.Err(), io.EOF))
+}
+
+func TestUnzip(t *testing.T) {
+	zipped := Zip(newTestLineSeq("a", "b", "c"), newTestLineSeq("1", "2", "3"))
+	firsts, seconds := Unzip(zipped)
+	// Read all of the second sequence first, forcing the first sequence to be buffered
+	assert.Equal(t, []string{"1", "2", "3"}, collectSeq(t, seconds))
+	assert.Equal(t, []string{"a", "b", "c"}, collectSeq(t, firsts))
+	testEof(t, firsts)
+	testEof(t, seconds)
+}
+
+func TestZipSideError(t *testing.T) {
+	errBoom := errors.New("boom")
+	sq := Zip(newTestLineSeq("a", "b"), &errAfterSeq{newTestLineSeq("1"), errBoom})
+	val, err := sq.Next()
+	testNextOk(t, Pair[string, string]{"a", "1"}, val, err)
+	_, err = sq.Next()
+	var sideErr *SideError
+	assert.True(t, errors.As(err, &sideErr))
+	assert.Equal(t, SideRight, sideErr.Side)
+}
+
+func TestZipAfterEof(t *testing.T) {
+	left := newTestLineSeq("a", "b", "c", "d")
+	sq := Zip(left, newTestLineSeq("1"))
+	val, err := sq.Next()
+	testNextOk(t, Pair[string, string]{"a", "1"}, val, err)
+	// "b" was read before the right side turned out to have ended, but nothing after it is
+	testEof(t, sq)
+	testEof(t, sq)
+	testEof(t, sq)
+	name, err := left.Next()
+	testNextOk(t, "c", name, err)
+
+	left = newTestLineSeq("a", "b", "c")
+	sqN := ZipN(left, newTestLineSeq("1"))
+	sqN.Next()
+	testEof(t, sqN)
+	testEof(t, sqN)
+	name, err = left.Next()
+	testNextOk(t, "c", name, err)
+}